@@ -0,0 +1,184 @@
+package gofpdf
+
+// Contextual shaping of Arabic text. Each letter is replaced by its isolated,
+// final, initial or medial presentation form, depending on whether it joins
+// the letters around it. The presentation forms are taken from the Arabic
+// Presentation Forms blocks of Unicode, which most fonts with Arabic support
+// cover.
+
+import (
+	"unicode"
+)
+
+type arabicFormsType struct {
+	isolated rune // first presentation form; the others follow consecutively
+	dual     bool // true if the letter joins on both sides, false if it joins only to the right
+}
+
+// arabicForms lists the letters that have presentation forms
+var arabicForms = map[rune]arabicFormsType{
+	0x0622: {0xFE81, false}, // alef with madda above
+	0x0623: {0xFE83, false}, // alef with hamza above
+	0x0624: {0xFE85, false}, // waw with hamza above
+	0x0625: {0xFE87, false}, // alef with hamza below
+	0x0626: {0xFE89, true},  // yeh with hamza above
+	0x0627: {0xFE8D, false}, // alef
+	0x0628: {0xFE8F, true},  // beh
+	0x0629: {0xFE93, false}, // teh marbuta
+	0x062A: {0xFE95, true},  // teh
+	0x062B: {0xFE99, true},  // theh
+	0x062C: {0xFE9D, true},  // jeem
+	0x062D: {0xFEA1, true},  // hah
+	0x062E: {0xFEA5, true},  // khah
+	0x062F: {0xFEA9, false}, // dal
+	0x0630: {0xFEAB, false}, // thal
+	0x0631: {0xFEAD, false}, // reh
+	0x0632: {0xFEAF, false}, // zain
+	0x0633: {0xFEB1, true},  // seen
+	0x0634: {0xFEB5, true},  // sheen
+	0x0635: {0xFEB9, true},  // sad
+	0x0636: {0xFEBD, true},  // dad
+	0x0637: {0xFEC1, true},  // tah
+	0x0638: {0xFEC5, true},  // zah
+	0x0639: {0xFEC9, true},  // ain
+	0x063A: {0xFECD, true},  // ghain
+	0x0641: {0xFED1, true},  // feh
+	0x0642: {0xFED5, true},  // qaf
+	0x0643: {0xFED9, true},  // kaf
+	0x0644: {0xFEDD, true},  // lam
+	0x0645: {0xFEE1, true},  // meem
+	0x0646: {0xFEE5, true},  // noon
+	0x0647: {0xFEE9, true},  // heh
+	0x0648: {0xFEED, false}, // waw
+	0x0649: {0xFEEF, false}, // alef maksura
+	0x064A: {0xFEF1, true},  // yeh
+	0x0671: {0xFB50, false}, // alef wasla
+	0x0679: {0xFB66, true},  // tteh
+	0x067E: {0xFB56, true},  // peh
+	0x0686: {0xFB7A, true},  // tcheh
+	0x0688: {0xFB88, false}, // ddal
+	0x0691: {0xFB8C, false}, // rreh
+	0x0698: {0xFB8A, false}, // jeh
+	0x06A4: {0xFB6A, true},  // veh
+	0x06A9: {0xFB8E, true},  // keheh
+	0x06AF: {0xFB92, true},  // gaf
+	0x06BA: {0xFB9E, false}, // noon ghunna
+	0x06BE: {0xFBAA, true},  // heh doachashmee
+	0x06C1: {0xFBA6, true},  // heh goal
+	0x06CC: {0xFBFC, true},  // farsi yeh
+	0x06D2: {0xFBAE, false}, // yeh barree
+}
+
+// arabicLamAlef maps the alef variants to the isolated form of their
+// ligature with a preceding lam; the final form follows it
+var arabicLamAlef = map[rune]rune{
+	0x0622: 0xFEF5,
+	0x0623: 0xFEF7,
+	0x0625: 0xFEF9,
+	0x0627: 0xFEFB,
+}
+
+type arabicJoiningType int
+
+const (
+	arabicJoinNone        arabicJoiningType = iota // does not join
+	arabicJoinRight                                // joins to the preceding letter only
+	arabicJoinDual                                 // joins on both sides
+	arabicJoinCausing                              // tatweel and zero width joiner
+	arabicJoinTransparent                          // marks, skipped when determining joins
+)
+
+func arabicJoining(r rune) arabicJoiningType {
+	if forms, ok := arabicForms[r]; ok {
+		if forms.dual {
+			return arabicJoinDual
+		}
+		return arabicJoinRight
+	}
+	switch r {
+	case 0x0640, 0x200D:
+		return arabicJoinCausing
+	}
+	if unicode.In(r, unicode.Mn, unicode.Me) || r == 0x200B {
+		return arabicJoinTransparent
+	}
+	return arabicJoinNone
+}
+
+// arabicShape returns runes with each Arabic letter replaced by the
+// presentation form that corresponds to its position in a word. A lam that is
+// followed by an alef is replaced by a ligature, and the position of the alef
+// is set to -1. Forms for which has returns false are not used. The returned
+// slice has the same length as runes; runes itself is not modified.
+func arabicShape(runes []rune, has func(rune) bool) (shaped []rune) {
+	shaped = make([]rune, len(runes))
+	copy(shaped, runes)
+	arabic := false
+	for _, r := range runes {
+		if r >= 0x0600 && r <= 0x06FF {
+			arabic = true
+			break
+		}
+	}
+	if !arabic {
+		return
+	}
+	types := make([]arabicJoiningType, len(runes))
+	for j, r := range runes {
+		types[j] = arabicJoining(r)
+	}
+	// Lam-alef ligatures take the joining type of the alef
+	lamAlef := make([]bool, len(runes))
+	for j := 0; j+1 < len(runes); j++ {
+		if runes[j] == 0x0644 {
+			if lig, ok := arabicLamAlef[runes[j+1]]; ok && has(lig) && has(lig+1) {
+				lamAlef[j] = true
+				types[j] = arabicJoinRight
+			}
+		}
+	}
+	neighbor := func(j, step int) arabicJoiningType {
+		for k := j + step; k >= 0 && k < len(runes); k += step {
+			if types[k] != arabicJoinTransparent {
+				return types[k]
+			}
+		}
+		return arabicJoinNone
+	}
+	for j := 0; j < len(runes); j++ {
+		t := types[j]
+		if t != arabicJoinRight && t != arabicJoinDual {
+			continue
+		}
+		prev := neighbor(j, -1)
+		next := neighbor(j, 1)
+		joinsPrev := prev == arabicJoinDual || prev == arabicJoinCausing
+		joinsNext := t == arabicJoinDual && next != arabicJoinNone
+		if lamAlef[j] {
+			lig := arabicLamAlef[runes[j+1]]
+			if joinsPrev {
+				lig++
+			}
+			shaped[j] = lig
+			shaped[j+1] = -1
+			j++
+			continue
+		}
+		forms := arabicForms[runes[j]]
+		var form rune
+		switch {
+		case joinsPrev && joinsNext:
+			form = forms.isolated + 3
+		case joinsNext:
+			form = forms.isolated + 2
+		case joinsPrev:
+			form = forms.isolated + 1
+		default:
+			form = forms.isolated
+		}
+		if has(form) {
+			shaped[j] = form
+		}
+	}
+	return
+}
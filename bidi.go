@@ -0,0 +1,730 @@
+package gofpdf
+
+// Implementation of the Unicode Bidirectional Algorithm (UAX #9) for a
+// single line of text. Character classes are derived from a compact range
+// table and the general categories of the unicode package, which is
+// sufficient for the scripts that are written from right to left and for the
+// neutral and numeric characters that commonly accompany them.
+
+import (
+	"sort"
+	"unicode"
+)
+
+type bidiClass uint8
+
+const (
+	bidiL bidiClass = iota
+	bidiR
+	bidiAL
+	bidiEN
+	bidiES
+	bidiET
+	bidiAN
+	bidiCS
+	bidiNSM
+	bidiBN
+	bidiB
+	bidiS
+	bidiWS
+	bidiON
+	bidiLRE
+	bidiLRO
+	bidiRLE
+	bidiRLO
+	bidiPDF
+	bidiLRI
+	bidiRLI
+	bidiFSI
+	bidiPDI
+)
+
+// bidiMaxDepth is the maximum explicit embedding level
+const bidiMaxDepth = 125
+
+type bidiRangeType struct {
+	lo, hi rune
+	class  bidiClass
+}
+
+// bidiRanges lists, in ascending order, the characters whose class is not
+// left-to-right (L) and is not implied by their general category. Nonspacing
+// marks are NSM and other punctuation and symbols are ON.
+var bidiRanges = []bidiRangeType{
+	{0x0000, 0x0008, bidiBN},
+	{0x0009, 0x0009, bidiS},
+	{0x000A, 0x000A, bidiB},
+	{0x000B, 0x000B, bidiS},
+	{0x000C, 0x000C, bidiWS},
+	{0x000D, 0x000D, bidiB},
+	{0x000E, 0x001B, bidiBN},
+	{0x001C, 0x001E, bidiB},
+	{0x001F, 0x001F, bidiS},
+	{0x0020, 0x0020, bidiWS},
+	{0x0023, 0x0025, bidiET},
+	{0x002B, 0x002B, bidiES},
+	{0x002C, 0x002C, bidiCS},
+	{0x002D, 0x002D, bidiES},
+	{0x002E, 0x002F, bidiCS},
+	{0x0030, 0x0039, bidiEN},
+	{0x003A, 0x003A, bidiCS},
+	{0x007F, 0x0084, bidiBN},
+	{0x0085, 0x0085, bidiB},
+	{0x0086, 0x009F, bidiBN},
+	{0x00A0, 0x00A0, bidiCS},
+	{0x00A2, 0x00A5, bidiET},
+	{0x00AD, 0x00AD, bidiBN},
+	{0x00B0, 0x00B1, bidiET},
+	{0x00B2, 0x00B3, bidiEN},
+	{0x00B9, 0x00B9, bidiEN},
+	{0x0590, 0x05FF, bidiR},
+	{0x0600, 0x0605, bidiAN},
+	{0x0606, 0x0608, bidiAL},
+	{0x0609, 0x060A, bidiET},
+	{0x060B, 0x060B, bidiAL},
+	{0x060C, 0x060C, bidiCS},
+	{0x060D, 0x065F, bidiAL},
+	{0x0660, 0x0669, bidiAN},
+	{0x066A, 0x066A, bidiET},
+	{0x066B, 0x066C, bidiAN},
+	{0x066D, 0x06DC, bidiAL},
+	{0x06DD, 0x06DD, bidiAN},
+	{0x06DE, 0x06EF, bidiAL},
+	{0x06F0, 0x06F9, bidiEN},
+	{0x06FA, 0x07BF, bidiAL},
+	{0x07C0, 0x085F, bidiR},
+	{0x0860, 0x08E1, bidiAL},
+	{0x08E2, 0x08E2, bidiAN},
+	{0x08E3, 0x08FF, bidiAL},
+	{0x1680, 0x1680, bidiWS},
+	{0x180E, 0x180E, bidiBN},
+	{0x2000, 0x200A, bidiWS},
+	{0x200B, 0x200D, bidiBN},
+	{0x200E, 0x200E, bidiL},
+	{0x200F, 0x200F, bidiR},
+	{0x2028, 0x2028, bidiWS},
+	{0x2029, 0x2029, bidiB},
+	{0x202A, 0x202A, bidiLRE},
+	{0x202B, 0x202B, bidiRLE},
+	{0x202C, 0x202C, bidiPDF},
+	{0x202D, 0x202D, bidiLRO},
+	{0x202E, 0x202E, bidiRLO},
+	{0x202F, 0x202F, bidiCS},
+	{0x2030, 0x2034, bidiET},
+	{0x2044, 0x2044, bidiCS},
+	{0x205F, 0x205F, bidiWS},
+	{0x2060, 0x2064, bidiBN},
+	{0x2066, 0x2066, bidiLRI},
+	{0x2067, 0x2067, bidiRLI},
+	{0x2068, 0x2068, bidiFSI},
+	{0x2069, 0x2069, bidiPDI},
+	{0x2070, 0x2070, bidiEN},
+	{0x2074, 0x2079, bidiEN},
+	{0x207A, 0x207B, bidiES},
+	{0x2080, 0x2089, bidiEN},
+	{0x208A, 0x208B, bidiES},
+	{0x20A0, 0x20CF, bidiET},
+	{0x212E, 0x212E, bidiET},
+	{0x2212, 0x2212, bidiES},
+	{0x2213, 0x2213, bidiET},
+	{0x2488, 0x249B, bidiEN},
+	{0x3000, 0x3000, bidiWS},
+	{0xFB1D, 0xFB28, bidiR},
+	{0xFB29, 0xFB29, bidiES},
+	{0xFB2A, 0xFB4F, bidiR},
+	{0xFB50, 0xFDCF, bidiAL},
+	{0xFDF0, 0xFDFF, bidiAL},
+	{0xFE50, 0xFE50, bidiCS},
+	{0xFE52, 0xFE52, bidiCS},
+	{0xFE55, 0xFE55, bidiCS},
+	{0xFE5F, 0xFE5F, bidiET},
+	{0xFE62, 0xFE63, bidiES},
+	{0xFE69, 0xFE6A, bidiET},
+	{0xFE70, 0xFEFE, bidiAL},
+	{0xFEFF, 0xFEFF, bidiBN},
+	{0xFF03, 0xFF05, bidiET},
+	{0xFF0B, 0xFF0B, bidiES},
+	{0xFF0C, 0xFF0C, bidiCS},
+	{0xFF0D, 0xFF0D, bidiES},
+	{0xFF0E, 0xFF0F, bidiCS},
+	{0xFF10, 0xFF19, bidiEN},
+	{0xFF1A, 0xFF1A, bidiCS},
+	{0xFFE0, 0xFFE1, bidiET},
+	{0xFFE5, 0xFFE6, bidiET},
+	{0x10800, 0x10FFF, bidiR},
+	{0x1E800, 0x1EDFF, bidiR},
+	{0x1EE00, 0x1EEFF, bidiAL},
+	{0x1EF00, 0x1EFFF, bidiR},
+}
+
+// bidiClassOf returns the bidirectional character type of r
+func bidiClassOf(r rune) bidiClass {
+	if unicode.In(r, unicode.Mn, unicode.Me) {
+		return bidiNSM
+	}
+	j := sort.Search(len(bidiRanges), func(j int) bool { return bidiRanges[j].hi >= r })
+	if j < len(bidiRanges) && bidiRanges[j].lo <= r {
+		return bidiRanges[j].class
+	}
+	if unicode.In(r, unicode.P, unicode.S) {
+		return bidiON
+	}
+	return bidiL
+}
+
+// bidiMirrors maps characters to their mirror images, which are displayed in
+// place of the characters in right-to-left text
+var bidiMirrors = map[rune]rune{
+	'(': ')', ')': '(', '<': '>', '>': '<', '[': ']', ']': '[', '{': '}', '}': '{',
+	'«': '»', '»': '«', '‹': '›', '›': '‹', '⁅': '⁆', '⁆': '⁅', '⁽': '⁾', '⁾': '⁽',
+	'₍': '₎', '₎': '₍', '≤': '≥', '≥': '≤', '≪': '≫', '≫': '≪', '⌈': '⌉', '⌉': '⌈',
+	'⌊': '⌋', '⌋': '⌊', '\u2329': '\u232A', '\u232A': '\u2329', '〈': '〉', '〉': '〈',
+	'⟨': '⟩', '⟩': '⟨', '《': '》', '》': '《', '「': '」', '」': '「', '『': '』', '』': '『', '【': '】', '】': '【',
+}
+
+// bidiBrackets maps opening paired brackets to their closing counterparts
+var bidiBrackets = map[rune]rune{
+	'(': ')', '[': ']', '{': '}', '⁅': '⁆', '⁽': '⁾', '₍': '₎', '⌈': '⌉', '⌊': '⌋',
+	'〈': '〉', '⟨': '⟩', '《': '》', '「': '」', '『': '』', '【': '】',
+}
+
+// bidiHasRTL returns true if any character of runes is written from right to
+// left or is an explicit directional formatting character
+func bidiHasRTL(runes []rune) bool {
+	for _, r := range runes {
+		switch bidiClassOf(r) {
+		case bidiR, bidiAL, bidiAN, bidiRLE, bidiRLO, bidiRLI, bidiFSI:
+			return true
+		}
+	}
+	return false
+}
+
+// bidiIsRemoved returns true for the classes that rule X9 removes from
+// further processing
+func bidiIsRemoved(c bidiClass) bool {
+	switch c {
+	case bidiLRE, bidiRLE, bidiLRO, bidiRLO, bidiPDF, bidiBN:
+		return true
+	}
+	return false
+}
+
+// bidiIsControl returns true if r is an explicit directional formatting
+// character that is not displayed
+func bidiIsControl(r rune) bool {
+	switch r {
+	case 0x061C, 0x200E, 0x200F:
+		return true
+	}
+	switch bidiClassOf(r) {
+	case bidiLRE, bidiRLE, bidiLRO, bidiRLO, bidiPDF, bidiLRI, bidiRLI, bidiFSI, bidiPDI:
+		return true
+	}
+	return false
+}
+
+// bidiStrongLevel returns the paragraph embedding level implied by the first
+// strong character of classes (rules P2 and P3), skipping isolated text.
+// Scanning ends at a paragraph separator or at an unmatched PDI. dflt is
+// returned if no strong character is found.
+func bidiStrongLevel(classes []bidiClass, dflt int) int {
+	isolates := 0
+	for _, c := range classes {
+		switch c {
+		case bidiL:
+			if isolates == 0 {
+				return 0
+			}
+		case bidiR, bidiAL:
+			if isolates == 0 {
+				return 1
+			}
+		case bidiLRI, bidiRLI, bidiFSI:
+			isolates++
+		case bidiPDI:
+			if isolates == 0 {
+				return dflt
+			}
+			isolates--
+		case bidiB:
+			return dflt
+		}
+	}
+	return dflt
+}
+
+// bidiLevels returns the resolved embedding level of each character of a
+// line of text for the specified paragraph level (0 for left-to-right, 1 for
+// right-to-left). The rules for line ends (L1) are applied.
+func bidiLevels(runes []rune, paraLevel int) (levels []int) {
+	n := len(runes)
+	orig := make([]bidiClass, n)
+	for j, r := range runes {
+		orig[j] = bidiClassOf(r)
+	}
+	classes := make([]bidiClass, n)
+	copy(classes, orig)
+	levels = make([]int, n)
+	// Matching isolate initiators and PDIs (BD9)
+	matchPDI := make([]int, n)
+	matchInit := make([]int, n)
+	for j := range matchPDI {
+		matchPDI[j] = -1
+		matchInit[j] = -1
+	}
+	var openList []int
+	for j, c := range orig {
+		switch c {
+		case bidiLRI, bidiRLI, bidiFSI:
+			openList = append(openList, j)
+		case bidiPDI:
+			if len(openList) > 0 {
+				k := openList[len(openList)-1]
+				openList = openList[:len(openList)-1]
+				matchPDI[k] = j
+				matchInit[j] = k
+			}
+		}
+	}
+	// Explicit levels and directions (X1 - X8)
+	type entryType struct {
+		level    int
+		override bidiClass // bidiON for none, otherwise bidiL or bidiR
+		isolate  bool
+	}
+	stack := []entryType{{level: paraLevel, override: bidiON}}
+	overflowIsolates, overflowEmbeddings, validIsolates := 0, 0, 0
+	nextLevel := func(rtl bool) int {
+		level := stack[len(stack)-1].level
+		if rtl {
+			return (level + 1) | 1
+		}
+		return (level + 2) &^ 1
+	}
+	for j := 0; j < n; j++ {
+		top := stack[len(stack)-1]
+		switch c := orig[j]; c {
+		case bidiRLE, bidiLRE, bidiRLO, bidiLRO:
+			levels[j] = top.level
+			level := nextLevel(c == bidiRLE || c == bidiRLO)
+			if level <= bidiMaxDepth && overflowIsolates == 0 && overflowEmbeddings == 0 {
+				entry := entryType{level: level, override: bidiON}
+				if c == bidiRLO {
+					entry.override = bidiR
+				} else if c == bidiLRO {
+					entry.override = bidiL
+				}
+				stack = append(stack, entry)
+			} else if overflowIsolates == 0 {
+				overflowEmbeddings++
+			}
+		case bidiRLI, bidiLRI, bidiFSI:
+			levels[j] = top.level
+			if top.override != bidiON {
+				classes[j] = top.override
+			}
+			rtl := c == bidiRLI
+			if c == bidiFSI {
+				end := n
+				if matchPDI[j] >= 0 {
+					end = matchPDI[j]
+				}
+				rtl = bidiStrongLevel(orig[j+1:end], 0) == 1
+			}
+			level := nextLevel(rtl)
+			if level <= bidiMaxDepth && overflowIsolates == 0 && overflowEmbeddings == 0 {
+				validIsolates++
+				stack = append(stack, entryType{level: level, override: bidiON, isolate: true})
+			} else {
+				overflowIsolates++
+			}
+		case bidiPDI:
+			if overflowIsolates > 0 {
+				overflowIsolates--
+			} else if validIsolates > 0 {
+				overflowEmbeddings = 0
+				for !stack[len(stack)-1].isolate {
+					stack = stack[:len(stack)-1]
+				}
+				stack = stack[:len(stack)-1]
+				validIsolates--
+			}
+			top = stack[len(stack)-1]
+			levels[j] = top.level
+			if top.override != bidiON {
+				classes[j] = top.override
+			}
+		case bidiPDF:
+			levels[j] = top.level
+			if overflowIsolates > 0 {
+			} else if overflowEmbeddings > 0 {
+				overflowEmbeddings--
+			} else if !top.isolate && len(stack) >= 2 {
+				stack = stack[:len(stack)-1]
+			}
+		case bidiB:
+			levels[j] = paraLevel
+		case bidiBN:
+			levels[j] = top.level
+		default:
+			levels[j] = top.level
+			if top.override != bidiON {
+				classes[j] = top.override
+			}
+		}
+	}
+	// Level runs of the characters that are not removed by rule X9
+	var runs [][]int
+	var run []int
+	for j := 0; j < n; j++ {
+		if bidiIsRemoved(orig[j]) {
+			continue
+		}
+		if len(run) > 0 && levels[run[0]] != levels[j] {
+			runs = append(runs, run)
+			run = nil
+		}
+		run = append(run, j)
+	}
+	if len(run) > 0 {
+		runs = append(runs, run)
+	}
+	// Isolating run sequences (BD13)
+	runOf := make(map[int]int)
+	for k, run := range runs {
+		runOf[run[0]] = k
+	}
+	for _, run := range runs {
+		first := run[0]
+		if orig[first] == bidiPDI && matchInit[first] >= 0 {
+			// Continuation of the sequence of the matching isolate initiator
+			continue
+		}
+		seq := append([]int(nil), run...)
+		for {
+			last := seq[len(seq)-1]
+			c := orig[last]
+			if (c == bidiLRI || c == bidiRLI || c == bidiFSI) && matchPDI[last] >= 0 {
+				if next, ok := runOf[matchPDI[last]]; ok {
+					seq = append(seq, runs[next]...)
+					continue
+				}
+			}
+			break
+		}
+		bidiResolveSequence(seq, runes, orig, classes, levels, paraLevel)
+	}
+	// Line ends (L1)
+	trailing := true
+	for j := n - 1; j >= 0; j-- {
+		switch orig[j] {
+		case bidiS, bidiB:
+			levels[j] = paraLevel
+			trailing = true
+		case bidiWS, bidiLRI, bidiRLI, bidiFSI, bidiPDI:
+			if trailing {
+				levels[j] = paraLevel
+			}
+		default:
+			if bidiIsRemoved(orig[j]) {
+				if trailing {
+					levels[j] = paraLevel
+				}
+			} else {
+				trailing = false
+			}
+		}
+	}
+	// Characters removed by X9 take the level of the preceding character so
+	// that they do not interrupt reordering
+	for j := 0; j < n; j++ {
+		if bidiIsRemoved(orig[j]) && orig[j] != bidiB {
+			if j > 0 {
+				levels[j] = levels[j-1]
+			} else {
+				levels[j] = paraLevel
+			}
+		}
+	}
+	return
+}
+
+// bidiStrong returns the strong direction of class c for the purpose of the
+// neutral rules, in which numbers count as right-to-left: bidiL, bidiR or
+// bidiON if c is not strong.
+func bidiStrong(c bidiClass) bidiClass {
+	switch c {
+	case bidiL:
+		return bidiL
+	case bidiR, bidiAL, bidiEN, bidiAN:
+		return bidiR
+	}
+	return bidiON
+}
+
+// bidiResolveSequence applies the weak type rules (W1 - W7), the neutral
+// type rules (N0 - N2) and the implicit level rules (I1, I2) to the
+// isolating run sequence seq
+func bidiResolveSequence(seq []int, runes []rune, orig, classes []bidiClass, levels []int, paraLevel int) {
+	n := len(runes)
+	level := levels[seq[0]]
+	dirOf := func(l int) bidiClass {
+		if l&1 != 0 {
+			return bidiR
+		}
+		return bidiL
+	}
+	// Start and end of sequence types
+	prevLevel := paraLevel
+	for j := seq[0] - 1; j >= 0; j-- {
+		if !bidiIsRemoved(orig[j]) {
+			prevLevel = levels[j]
+			break
+		}
+	}
+	nextLevel := paraLevel
+	last := seq[len(seq)-1]
+	// A sequence that ends with an isolate initiator is followed by the
+	// paragraph level
+	if c := orig[last]; c != bidiLRI && c != bidiRLI && c != bidiFSI {
+		for j := last + 1; j < n; j++ {
+			if !bidiIsRemoved(orig[j]) {
+				nextLevel = levels[j]
+				break
+			}
+		}
+	}
+	sos := dirOf(maxInt(level, prevLevel))
+	eos := dirOf(maxInt(level, nextLevel))
+	types := make([]bidiClass, len(seq))
+	for k, j := range seq {
+		types[k] = classes[j]
+	}
+	isIsolate := func(c bidiClass) bool {
+		return c == bidiLRI || c == bidiRLI || c == bidiFSI || c == bidiPDI
+	}
+	// W1
+	prev := sos
+	for k, c := range types {
+		if c == bidiNSM {
+			if isIsolate(prev) {
+				types[k] = bidiON
+			} else {
+				types[k] = prev
+			}
+		}
+		prev = types[k]
+	}
+	// W2, W3
+	strong := sos
+	for k, c := range types {
+		switch c {
+		case bidiL, bidiR:
+			strong = c
+		case bidiAL:
+			strong = c
+			types[k] = bidiR
+		case bidiEN:
+			if strong == bidiAL {
+				types[k] = bidiAN
+			}
+		}
+	}
+	// W4
+	for k := 1; k+1 < len(types); k++ {
+		c, before, after := types[k], types[k-1], types[k+1]
+		if c == bidiES && before == bidiEN && after == bidiEN {
+			types[k] = bidiEN
+		} else if c == bidiCS && before == after && (before == bidiEN || before == bidiAN) {
+			types[k] = before
+		}
+	}
+	// W5
+	for k := 0; k < len(types); k++ {
+		if types[k] != bidiET {
+			continue
+		}
+		end := k
+		for end < len(types) && types[end] == bidiET {
+			end++
+		}
+		if (k > 0 && types[k-1] == bidiEN) || (end < len(types) && types[end] == bidiEN) {
+			for m := k; m < end; m++ {
+				types[m] = bidiEN
+			}
+		}
+		k = end - 1
+	}
+	// W6
+	for k, c := range types {
+		if c == bidiES || c == bidiET || c == bidiCS {
+			types[k] = bidiON
+		}
+	}
+	// W7
+	strong = sos
+	for k, c := range types {
+		switch c {
+		case bidiL, bidiR:
+			strong = c
+		case bidiEN:
+			if strong == bidiL {
+				types[k] = bidiL
+			}
+		}
+	}
+	// N0: paired brackets
+	embedDir := dirOf(level)
+	type pairType struct{ open, close int }
+	var pairs []pairType
+	type openType struct {
+		close rune
+		pos   int
+	}
+	var openStack []openType
+	for k, j := range seq {
+		if types[k] != bidiON {
+			continue
+		}
+		r := runes[j]
+		// Canonically equivalent angle brackets match each other
+		if r == '\u2329' {
+			r = '〈'
+		} else if r == '\u232A' {
+			r = '〉'
+		}
+		if closeRune, ok := bidiBrackets[r]; ok {
+			if len(openStack) == 63 {
+				break
+			}
+			openStack = append(openStack, openType{closeRune, k})
+			continue
+		}
+		for m := len(openStack) - 1; m >= 0; m-- {
+			if openStack[m].close == r {
+				pairs = append(pairs, pairType{openStack[m].pos, k})
+				openStack = openStack[:m]
+				break
+			}
+		}
+	}
+	sort.Slice(pairs, func(a, b int) bool { return pairs[a].open < pairs[b].open })
+	for _, p := range pairs {
+		found := bidiON
+		for k := p.open + 1; k < p.close; k++ {
+			if s := bidiStrong(types[k]); s != bidiON {
+				if s == embedDir {
+					found = s
+					break
+				}
+				found = s
+			}
+		}
+		if found == bidiON {
+			continue
+		}
+		if found != embedDir {
+			context := sos
+			for k := p.open - 1; k >= 0; k-- {
+				if s := bidiStrong(types[k]); s != bidiON {
+					context = s
+					break
+				}
+			}
+			if context != found {
+				found = embedDir
+			}
+		}
+		types[p.open], types[p.close] = found, found
+		// Nonspacing marks that follow a bracket take its type
+		for _, pos := range []int{p.open, p.close} {
+			for k := pos + 1; k < len(types) && orig[seq[k]] == bidiNSM; k++ {
+				types[k] = found
+			}
+		}
+	}
+	// N1, N2
+	isNeutral := func(c bidiClass) bool {
+		switch c {
+		case bidiB, bidiS, bidiWS, bidiON, bidiLRI, bidiRLI, bidiFSI, bidiPDI:
+			return true
+		}
+		return false
+	}
+	for k := 0; k < len(types); k++ {
+		if !isNeutral(types[k]) {
+			continue
+		}
+		end := k
+		for end < len(types) && isNeutral(types[end]) {
+			end++
+		}
+		before, after := sos, eos
+		if k > 0 {
+			before = bidiStrong(types[k-1])
+		}
+		if end < len(types) {
+			after = bidiStrong(types[end])
+		}
+		dir := embedDir
+		if before == after {
+			dir = before
+		}
+		for m := k; m < end; m++ {
+			types[m] = dir
+		}
+		k = end - 1
+	}
+	// I1, I2
+	for k, j := range seq {
+		switch c := types[k]; {
+		case level&1 == 0 && c == bidiR:
+			levels[j]++
+		case level&1 == 0 && (c == bidiAN || c == bidiEN):
+			levels[j] += 2
+		case level&1 != 0 && (c == bidiL || c == bidiEN || c == bidiAN):
+			levels[j]++
+		}
+	}
+}
+
+// bidiVisualOrder returns the indexes of the characters with the specified
+// resolved levels in the order in which they are displayed (rule L2)
+func bidiVisualOrder(levels []int) (order []int) {
+	order = make([]int, len(levels))
+	high, low := 0, bidiMaxDepth+2
+	for j, l := range levels {
+		order[j] = j
+		if l > high {
+			high = l
+		}
+		if l&1 != 0 && l < low {
+			low = l
+		}
+	}
+	for level := high; level >= low; level-- {
+		for j := 0; j < len(order); {
+			if levels[order[j]] < level {
+				j++
+				continue
+			}
+			k := j
+			for k < len(order) && levels[order[k]] >= level {
+				k++
+			}
+			for a, b := j, k-1; a < b; a, b = a+1, b-1 {
+				order[a], order[b] = order[b], order[a]
+			}
+			j = k
+		}
+	}
+	return
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
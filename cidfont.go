@@ -0,0 +1,269 @@
+package gofpdf
+
+// Standard CJK fonts that are not embedded in the document. PDF viewers
+// provide these fonts, or a substitute for them, by means of the Adobe Asian
+// font packs. Text is encoded with the UCS-2 CMaps of the character
+// collections, so that each character is shown with its Unicode value.
+
+import (
+	"fmt"
+	"strings"
+)
+
+type cidFontType struct {
+	ordering   string  // character collection of the Adobe registry, for example "Japan1"
+	supplement int     // supplement of the character collection
+	cmap       string  // name of the predefined CMap, for example "UniJIS-UCS2-H"
+	vertical   bool    // vertical writing mode
+	ascii      [95]int // widths of the proportional characters U+0020 through U+007E
+	halfWd     bool    // half-width katakana U+FF61 through U+FF9F are 500 units wide
+}
+
+// cidFonts describes the standard font of each character collection
+var cidFonts = map[string]struct {
+	nameStr    string
+	cmapStr    string
+	supplement int
+	ascii      [95]int
+	halfWd     bool
+}{
+	"Japan1": {"KozMinPro-Regular-Acro", "UniJIS-UCS2", 2, [95]int{
+		278, 299, 353, 614, 614, 721, 735, 216, 323, 323, 449, 529, 219, 306, 219, 453,
+		614, 614, 614, 614, 614, 614, 614, 614, 614, 614, 219, 219, 529, 529, 529, 486,
+		744, 646, 604, 617, 681, 567, 537, 647, 738, 320, 433, 637, 566, 904, 710, 716,
+		605, 716, 623, 517, 601, 690, 668, 990, 681, 634, 578, 316, 614, 316, 529, 500,
+		387, 509, 566, 478, 565, 503, 337, 549, 580, 275, 266, 544, 276, 854, 579, 550,
+		578, 566, 410, 444, 340, 575, 512, 760, 503, 529, 453, 326, 380, 326, 387}, true},
+	"GB1": {"STSongStd-Light-Acro", "UniGB-UCS2", 2, [95]int{
+		207, 270, 342, 467, 462, 797, 710, 239, 374, 374, 423, 605, 238, 375, 238, 334,
+		462, 462, 462, 462, 462, 462, 462, 462, 462, 462, 238, 238, 605, 605, 605, 344,
+		748, 684, 560, 695, 739, 563, 511, 729, 793, 318, 312, 666, 526, 896, 758, 772,
+		544, 772, 628, 465, 607, 753, 711, 972, 647, 620, 607, 374, 333, 374, 606, 500,
+		239, 417, 503, 427, 529, 415, 264, 444, 518, 241, 230, 495, 228, 793, 527, 524,
+		524, 504, 338, 336, 277, 517, 450, 652, 466, 452, 407, 370, 258, 370, 605}, false},
+	"CNS1": {"MSungStd-Light-Acro", "UniCNS-UCS2", 0, [95]int{
+		250, 250, 408, 668, 490, 875, 698, 250, 240, 240, 417, 667, 250, 313, 250, 520,
+		500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 250, 250, 667, 667, 667, 396,
+		921, 677, 615, 719, 760, 625, 552, 771, 802, 354, 354, 781, 604, 927, 750, 823,
+		563, 823, 729, 542, 698, 771, 729, 948, 771, 677, 635, 344, 520, 344, 469, 500,
+		250, 469, 521, 427, 521, 438, 271, 469, 531, 250, 250, 458, 240, 802, 531, 500,
+		521, 521, 365, 333, 292, 521, 458, 677, 479, 458, 427, 480, 496, 480, 667}, false},
+	"Korea1": {"HYSMyeongJoStd-Medium-Acro", "UniKS-UCS2", 0, [95]int{
+		333, 416, 416, 833, 625, 916, 833, 250, 500, 500, 500, 833, 291, 833, 291, 375,
+		625, 625, 625, 625, 625, 625, 625, 625, 625, 625, 333, 333, 833, 833, 916, 500,
+		1000, 791, 708, 708, 750, 708, 666, 750, 791, 375, 500, 791, 666, 916, 791, 750,
+		666, 750, 708, 666, 791, 791, 750, 1000, 708, 708, 666, 500, 375, 500, 500, 500,
+		333, 541, 583, 541, 583, 583, 375, 583, 583, 291, 333, 583, 291, 875, 583, 583,
+		583, 583, 458, 541, 375, 583, 583, 833, 625, 625, 500, 583, 583, 583, 750}, false},
+}
+
+// AddCIDFont makes one of the standard Chinese, Japanese or Korean fonts
+// available. These fonts are not embedded in the document; PDF viewers supply
+// them (or a similar font) from their Asian font packages, which keeps the
+// document small. Text written with the font is interpreted as UTF-8.
+//
+// orderingStr identifies the character collection: "Japan1" (Japanese,
+// KozMinPro-Regular-Acro), "GB1" (simplified Chinese, STSongStd-Light-Acro),
+// "CNS1" (traditional Chinese, MSungStd-Light-Acro) or "Korea1" (Korean,
+// HYSMyeongJoStd-Medium-Acro). Characters are encoded with the corresponding
+// Unicode CMap, for example UniJIS-UCS2-H, and are limited to the Basic
+// Multilingual Plane. The widths of the proportional Latin characters, and of
+// half-width katakana, are known, so GetStringWidth(), SplitLines() and
+// MultiCell() measure text correctly; all other characters are one em wide.
+//
+// If vertical is true, the vertical variant of the CMap, for example
+// UniJIS-UCS2-V, is used. Characters are then stacked from top to bottom and
+// each one advances by one em, which is the value GetStringWidth() returns
+// for each character. Vertical text is written with Text(), in which case x
+// is the center of the column and y is the top of the first character, or
+// with MultiCellVertical(). Other functions such as Cell() assume horizontal
+// text and should not be used with a vertical font. Underlining is not
+// supported in vertical text.
+//
+// familyStr and styleStr are as in AddFont(). Bold and italic styles are
+// requested from the viewer with the ",Bold", ",Italic" and ",BoldItalic"
+// suffixes of the font name; a viewer that does not have such a font
+// simulates the style.
+//
+// See tutorial 41 for an example of this function.
+func (f *Fpdf) AddCIDFont(familyStr, styleStr, orderingStr string, vertical bool) {
+	if f.err != nil {
+		return
+	}
+	familyStr = strings.ToLower(familyStr)
+	styleStr = strings.ToUpper(styleStr)
+	if styleStr == "IB" {
+		styleStr = "BI"
+	}
+	fontkey := familyStr + styleStr
+	if _, ok := f.fonts[fontkey]; ok {
+		return
+	}
+	std, ok := cidFonts[orderingStr]
+	if !ok {
+		f.err = fmt.Errorf("unknown CID character collection %s", orderingStr)
+		return
+	}
+	cid := &cidFontType{
+		ordering:   orderingStr,
+		supplement: std.supplement,
+		cmap:       std.cmapStr + "-H",
+		vertical:   vertical,
+		ascii:      std.ascii,
+		halfWd:     std.halfWd,
+	}
+	if vertical {
+		cid.cmap = std.cmapStr + "-V"
+	}
+	var def fontDefType
+	def.Tp = "CID"
+	def.Name = std.nameStr
+	def.Desc = fontDescType{Ascent: 880, Descent: -120, CapHeight: 880, Flags: 6,
+		FontBBox: fontBoxType{0, -200, 1000, 900}, StemV: 50, MissingWidth: 1000}
+	switch styleStr {
+	case "B":
+		def.Name += ",Bold"
+		def.Desc.StemV = 120
+	case "I":
+		def.Name += ",Italic"
+		def.Desc.ItalicAngle = -11
+	case "BI":
+		def.Name += ",BoldItalic"
+		def.Desc.StemV = 120
+		def.Desc.ItalicAngle = -11
+	}
+	def.Up = -130
+	def.Ut = 40
+	for j := range def.Cw {
+		def.Cw[j] = cid.width(rune(j))
+	}
+	def.cid = cid
+	def.I = len(f.fonts)
+	f.fonts[fontkey] = def
+}
+
+// width returns the advance of character r in thousandths of the font size
+func (cid *cidFontType) width(r rune) int {
+	switch {
+	case cid.vertical:
+		return 1000
+	case r >= 0x20 && r <= 0x7e:
+		return cid.ascii[r-0x20]
+	case cid.halfWd && r >= 0xff61 && r <= 0xff9f:
+		return 500
+	}
+	return 1000
+}
+
+// code returns the two-byte code of character r in the UCS-2 CMap
+func (cid *cidFontType) code(r rune) uint16 {
+	if r > 0xffff {
+		return 0
+	}
+	return uint16(r)
+}
+
+// putCIDFont writes the Type0 font, the CIDFont and the font descriptor of a
+// standard CJK font
+func (f *Fpdf) putCIDFont(font fontDefType) {
+	cid := font.cid
+	// Type0 font
+	f.newobj()
+	f.out("<</Type /Font /Subtype /Type0")
+	f.outf("/BaseFont /%s-%s", font.Name, cid.cmap)
+	f.outf("/Encoding /%s", cid.cmap)
+	f.outf("/DescendantFonts [%d 0 R]>>", f.n+1)
+	f.out("endobj")
+	// CIDFont; the proportional Latin characters of each collection have
+	// CIDs 1 through 95
+	f.newobj()
+	f.out("<</Type /Font /Subtype /CIDFontType0")
+	f.outf("/BaseFont /%s", font.Name)
+	f.outf("/CIDSystemInfo <</Registry (Adobe) /Ordering (%s) /Supplement %d>>", cid.ordering, cid.supplement)
+	f.outf("/FontDescriptor %d 0 R", f.n+1)
+	var s fmtBuffer
+	s.WriteString("/W [1 [")
+	for j, wd := range cid.ascii {
+		if j > 0 {
+			s.WriteString(" ")
+		}
+		s.printf("%d", wd)
+	}
+	s.WriteString("]")
+	if cid.halfWd {
+		// Half-width characters
+		s.WriteString(" 231 632 500")
+	}
+	s.WriteString("]>>")
+	f.out(s.String())
+	f.out("endobj")
+	// Descriptor
+	f.newobj()
+	s.Truncate(0)
+	s.printf("<</Type /FontDescriptor /FontName /%s ", font.Name)
+	s.printf("/Ascent %d ", font.Desc.Ascent)
+	s.printf("/Descent %d ", font.Desc.Descent)
+	s.printf("/CapHeight %d ", font.Desc.CapHeight)
+	s.printf("/Flags %d ", font.Desc.Flags)
+	s.printf("/FontBBox [%d %d %d %d] ", font.Desc.FontBBox.Xmin, font.Desc.FontBBox.Ymin,
+		font.Desc.FontBBox.Xmax, font.Desc.FontBBox.Ymax)
+	s.printf("/ItalicAngle %d ", font.Desc.ItalicAngle)
+	s.printf("/StemV %d ", font.Desc.StemV)
+	s.printf("/MissingWidth %d>>", font.Desc.MissingWidth)
+	f.out(s.String())
+	f.out("endobj")
+}
+
+// vertical returns true if the current font is a vertical CJK font
+func (f *Fpdf) vertical() bool {
+	return f.currentFont.cid != nil && f.currentFont.cid.vertical
+}
+
+// MultiCellVertical prints text in vertical columns with a vertical CJK font
+// (see AddCIDFont()). Characters are stacked from top to bottom and columns
+// follow each other from right to left, as is customary for Chinese, Japanese
+// and Korean. Columns are broken automatically when the text reaches the
+// bottom of the column and explicitly at each line feed.
+//
+// w is the width of each column, which corresponds to the line height of
+// MultiCell(). h is the height of the columns; a value of zero indicates
+// columns that reach to the bottom margin. The upper-left corner of the block
+// of columns is the current position.
+//
+// alignStr specifies the alignment of the text within each column: "T" for
+// top (the default), "M" for middle or "B" for bottom. borderStr and fill apply
+// to the block of columns as in CellFormat(). After the call, the current
+// position is at the left margin below the block.
+//
+// See tutorial 41 for an example of this function.
+func (f *Fpdf) MultiCellVertical(w, h float64, txtStr, borderStr, alignStr string, fill bool) {
+	if f.err != nil {
+		return
+	}
+	if !f.vertical() {
+		f.err = fmt.Errorf("MultiCellVertical requires a vertical CJK font")
+		return
+	}
+	if h == 0 {
+		h = f.pageBreakTrigger - f.y
+	}
+	lines := f.SplitLines([]byte(txtStr), h)
+	if len(lines) == 0 {
+		lines = [][]byte{nil}
+	}
+	wd := float64(len(lines)) * w
+	// Block, which may cause a page break
+	f.CellFormat(wd, h, "", borderStr, 0, "", fill, 0, "")
+	x, y := f.x-wd, f.y
+	for j, line := range lines {
+		str := string(line)
+		top := y + f.cMargin
+		if ht := f.GetStringWidth(str); strings.Contains(alignStr, "M") {
+			top = y + (h-ht)/2
+		} else if strings.Contains(alignStr, "B") {
+			top = y + h - f.cMargin - ht
+		}
+		f.Text(x+wd-(float64(j)+0.5)*w, top, str)
+	}
+	f.SetXY(f.lMargin, y+h)
+}
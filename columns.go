@@ -0,0 +1,239 @@
+package gofpdf
+
+// Text that flows through several columns on a page
+
+import (
+	"fmt"
+	"math"
+)
+
+// SetColumns sets the text that follows in count columns of equal width that
+// are separated by gutter, in the unit of measure specified in New(). The
+// columns share the space between the current left and right margins and
+// begin at the current vertical position.
+//
+// While columns are set, the left and right margins are those of the current
+// column, so that Cell(), MultiCell(), Write(), the Write() method of
+// HTMLBasicType and images placed in flowing mode fill one column at a time.
+// When the text reaches the page break trigger (see SetAutoPageBreak()), it
+// continues at the top of the next column instead of on a new page; a page
+// is added only after the last column is full. The columns of a new page
+// begin below its header, which is printed, like the footer, with the
+// margins of the page. Call EndColumns() to return to text that spans the
+// page, and BalanceColumns() to end a section with columns of equal height.
+// The margins must not be changed while columns are set.
+//
+// If columns are already set, they are ended as by EndColumns() before the
+// new ones begin.
+//
+// See tutorial 52 for an example of this function.
+func (f *Fpdf) SetColumns(count int, gutter float64) {
+	if count < 1 {
+		f.err = fmt.Errorf("invalid number of columns: %d", count)
+		return
+	}
+	f.EndColumns()
+	wd := (f.w - f.lMargin - f.rMargin - gutter*float64(count-1)) / float64(count)
+	widths := make([]float64, count)
+	for j := range widths {
+		widths[j] = wd
+	}
+	f.SetColumnWidths(widths, gutter)
+}
+
+// SetColumnWidths is like SetColumns() except that the width of each column
+// is specified in widths, in the unit of measure specified in New(). The
+// first column begins at the current left margin.
+//
+// See tutorial 52 for an example of this function.
+func (f *Fpdf) SetColumnWidths(widths []float64, gutter float64) {
+	if f.err != nil {
+		return
+	}
+	if len(widths) == 0 {
+		f.err = fmt.Errorf("no column widths specified")
+		return
+	}
+	for _, wd := range widths {
+		if wd <= 0 {
+			f.err = fmt.Errorf("invalid column width: %.2f", wd)
+			return
+		}
+	}
+	f.EndColumns()
+	f.columns = columnsType{
+		widths:  append([]float64(nil), widths...),
+		gutter:  gutter,
+		left:    f.lMargin,
+		lMargin: f.lMargin,
+		rMargin: f.rMargin,
+	}
+	f.startColumns(f.y)
+}
+
+// GetColumn returns the zero-based index of the column in which text is
+// currently set, or -1 if columns are not set with SetColumns() or
+// SetColumnWidths().
+func (f *Fpdf) GetColumn() int {
+	if len(f.columns.widths) == 0 {
+		return -1
+	}
+	return f.columns.col
+}
+
+// EndColumns ends the columns set with SetColumns() or SetColumnWidths(). The
+// margins of the page are restored and the current position is moved to the
+// left margin below the lowest column on the current page. The method does
+// nothing if columns are not set.
+//
+// See tutorial 52 for an example of this function.
+func (f *Fpdf) EndColumns() {
+	c := &f.columns
+	if len(c.widths) == 0 {
+		return
+	}
+	f.lMargin, f.rMargin = c.lMargin, c.rMargin
+	f.x, f.y = f.lMargin, math.Max(c.bottom, f.y)
+	f.columns = columnsType{}
+}
+
+// BalanceColumns calls fnc, which typically prints the text of a section with
+// MultiCell(), Write() or other functions that place text in cells, so that
+// the text is distributed among the columns set with SetColumns() or
+// SetColumnWidths() in columns of nearly equal height. The text begins at
+// the current position in the first column; if the current column is not the
+// first one, it begins below the lowest column. Afterwards, the current
+// position is at the left of the first column below the lowest column, where
+// the columns begin anew.
+//
+// The height of the columns is found by laying out the text repeatedly with
+// output suppressed, as with Measure(), so fnc is called several times and
+// must produce the same layout each time; it must not add pages. If the text
+// does not fit in the columns on the rest of the current page, it is not
+// balanced and flows through the columns and pages as usual. If columns are
+// not set, fnc is simply called.
+//
+// See tutorial 52 for an example of this function.
+func (f *Fpdf) BalanceColumns(fnc func()) {
+	if f.err != nil {
+		return
+	}
+	c := &f.columns
+	if len(c.widths) == 0 || f.silent {
+		fnc()
+		return
+	}
+	if c.col > 0 {
+		f.startColumns(math.Max(c.bottom, f.y))
+	} else {
+		c.top, c.bottom = f.y, f.y
+	}
+	trigger := f.pageBreakTrigger
+	restore := f.saveLayout()
+	state := *c
+	// fits returns true if the text fits in columns of height ht
+	fits := func(ht float64) bool {
+		f.pageBreakTrigger = c.top + ht
+		c.trial, c.overflow = true, false
+		f.silently(fnc)
+		ok := !c.overflow
+		restore()
+		*c = state
+		f.selectColumn(c.col)
+		f.pageBreakTrigger = trigger
+		return ok
+	}
+	if hi := trigger - c.top; fits(hi) {
+		// Bisect the height to within a thousandth of the space available
+		lo := 0.0
+		for hi-lo > (trigger-c.top)/1000 {
+			if mid := (lo + hi) / 2; fits(mid) {
+				hi = mid
+			} else {
+				lo = mid
+			}
+		}
+		f.pageBreakTrigger = c.top + hi
+	}
+	fnc()
+	f.pageBreakTrigger = trigger
+	if f.err != nil || len(c.widths) == 0 {
+		return
+	}
+	f.startColumns(math.Max(c.bottom, f.y))
+}
+
+// startColumns begins the columns at the vertical position y with the
+// current position at the top of the first column
+func (f *Fpdf) startColumns(y float64) {
+	c := &f.columns
+	c.top, c.bottom = y, y
+	f.selectColumn(0)
+	f.x, f.y = f.lMargin, y
+}
+
+// selectColumn sets the margins to the edges of column j
+func (f *Fpdf) selectColumn(j int) {
+	c := &f.columns
+	c.col = j
+	f.lMargin = c.left
+	for _, wd := range c.widths[:j] {
+		f.lMargin += wd + c.gutter
+	}
+	f.rMargin = f.w - f.lMargin - c.widths[j]
+}
+
+// columnOrigin returns the left edge of the current column, or 0 if columns
+// are not set
+func (f *Fpdf) columnOrigin() float64 {
+	if len(f.columns.widths) == 0 {
+		return 0
+	}
+	return f.lMargin
+}
+
+// nextColumn moves the current position to the top of the next column when
+// text that is set in columns reaches the page break trigger. The distance of
+// the current position from the left edge of the column is retained. The
+// return value is false if a page break is required instead. When columns
+// are balanced, text that does not fit in the last column is recorded and
+// continues below it.
+func (f *Fpdf) nextColumn() bool {
+	c := &f.columns
+	if len(c.widths) == 0 {
+		return false
+	}
+	c.bottom = math.Max(c.bottom, f.y)
+	if c.col == len(c.widths)-1 {
+		if c.trial {
+			c.overflow = true
+			return true
+		}
+		return false
+	}
+	x := f.x - f.lMargin
+	f.selectColumn(c.col + 1)
+	f.x, f.y = f.lMargin+x, c.top
+	return true
+}
+
+// autoBreak performs the column or page break that a line of height h at
+// the current position would cause, so that MultiCell() and Write() can fit
+// the line to the column or page on which it is printed. The distance of the
+// current position from the left margin is retained.
+func (f *Fpdf) autoBreak(h float64) {
+	if f.y+h <= f.pageBreakTrigger || f.inHeader || f.inFooter || !f.acceptPageBreak() || f.nextColumn() {
+		return
+	}
+	x, ws := f.x-f.lMargin, f.ws
+	if ws > 0 {
+		f.ws = 0
+		f.putWordSpacing()
+	}
+	f.AddPageFormat(f.curOrientation, f.curPageSize)
+	f.x = f.lMargin + x
+	if ws > 0 {
+		f.ws = ws
+		f.putWordSpacing()
+	}
+}
@@ -0,0 +1,148 @@
+package gofpdf
+
+// Underlines, strikethroughs and overlines of text
+
+import (
+	"math"
+	"strings"
+)
+
+// SetTextDecorationStyle specifies the appearance of the lines that are drawn
+// with text when the font style (see SetFont()) includes "U" (underline), "S"
+// (strikethrough) or "O" (overline). styleStr is "solid" (the default) for a
+// single line, "double" for two parallel lines or "dashed" for a broken line.
+//
+// thickness is the width of each line in the unit of measure specified in
+// New(). Zero, the default, uses the underline thickness specified by the
+// current font, so that the lines scale with the font size.
+//
+// offset displaces the lines from their normal positions, in the unit of
+// measure specified in New(). Positive values move underlines down and
+// strikethroughs and overlines up. Normally, underlines are placed at the
+// position specified by the font, strikethroughs at half the x-height and
+// overlines at the ascent of the font.
+//
+// The settings apply to Text(), Cell(), MultiCell(), Write() and the functions
+// that use them. The method can be called before the first page is created and
+// the values are retained from page to page.
+//
+// See tutorial 43 for an example of this function.
+func (f *Fpdf) SetTextDecorationStyle(styleStr string, thickness, offset float64) {
+	switch styleStr {
+	case "double", "dashed":
+		f.decoration.styleStr = styleStr
+	default:
+		f.decoration.styleStr = "solid"
+	}
+	f.decoration.thickness = thickness
+	f.decoration.offset = offset
+}
+
+// GetTextDecorationStyle returns the style, thickness and offset of text
+// decorations as set with SetTextDecorationStyle().
+func (f *Fpdf) GetTextDecorationStyle() (styleStr string, thickness, offset float64) {
+	return f.decoration.styleStr, f.decoration.thickness, f.decoration.offset
+}
+
+// SetTextDecorationColor defines the color of underlines, strikethroughs and
+// overlines. It is expressed in RGB components (0 - 255). By default, and
+// after this method is called with a negative value of r, the lines have the
+// color of the text (see SetTextColor()). The method can be called before the
+// first page is created and the value is retained from page to page.
+//
+// See tutorial 43 for an example of this function.
+func (f *Fpdf) SetTextDecorationColor(r, g, b int) {
+	f.decoration.clrSet = r >= 0
+	if f.decoration.clrSet {
+		f.decoration.clr = colorValue(r, g, b, "g", "rg")
+	}
+}
+
+// decorated returns true if text is currently underlined, struck through or
+// overlined
+func (f *Fpdf) decorated() bool {
+	return f.underline || f.strikeout || f.overline
+}
+
+// decorationStyle returns the letters of the current font style that select
+// decorations and small capitals, as used by SetFont()
+func (f *Fpdf) decorationStyle() (styleStr string) {
+	if f.underline {
+		styleStr += "U"
+	}
+	if f.strikeout {
+		styleStr += "S"
+	}
+	if f.overline {
+		styleStr += "O"
+	}
+	if f.synth.smallCaps {
+		styleStr += "C"
+	}
+	return
+}
+
+// dodecoration returns the PDF operators that draw the decorations of txt
+// written with its origin at (x, y)
+func (f *Fpdf) dodecoration(x, y float64, txt string) string {
+	w := f.GetStringWidth(txt) + f.ws*float64(blankCount(txt))
+	return f.decorate(x, y, w)
+}
+
+// decorate returns the PDF operators that draw the current decorations over a
+// width of w, starting at x on the baseline y
+func (f *Fpdf) decorate(x, y, w float64) string {
+	t := f.decoration.thickness
+	if t <= 0 {
+		t = float64(f.currentFont.Ut) / 1000 * f.fontSize
+		if t <= 0 {
+			t = 50.0 / 1000 * f.fontSize
+		}
+	}
+	off := f.decoration.offset
+	var s fmtBuffer
+	// line draws the decoration whose upper edge is at top; a second line of a
+	// double decoration is drawn at a distance of 2t in the direction dir
+	line := func(top, dir float64) {
+		tops := []float64{top}
+		if f.decoration.styleStr == "double" {
+			if dir == 0 {
+				tops = []float64{top - t, top + t}
+			} else {
+				tops = append(tops, top+dir*2*t)
+			}
+		}
+		for _, top := range tops {
+			if f.decoration.styleStr == "dashed" {
+				// The dashes are proportioned to the thickness, which is taken
+				// to be at least 0.1 point for this purpose so that a thin line
+				// does not produce an excessive number of dashes
+				u := math.Max(t, 0.1/f.k)
+				for dx := 0.0; dx < w; dx += 5 * u {
+					s.printf("%.2f %.2f %.2f %.2f re f ", (x+dx)*f.k, (f.h-top)*f.k,
+						math.Min(3*u, w-dx)*f.k, -t*f.k)
+				}
+			} else {
+				s.printf("%.2f %.2f %.2f %.2f re f ", x*f.k, (f.h-top)*f.k, w*f.k, -t*f.k)
+			}
+		}
+	}
+	if f.underline {
+		up := float64(f.currentFont.Up)
+		line(y-up/1000*f.fontSize+off, 1)
+	}
+	if f.strikeout || f.overline {
+		desc, xHeight := f.fontDesc()
+		if f.strikeout {
+			line(y-float64(xHeight)/2000*f.fontSize-t/2-off, 0)
+		}
+		if f.overline {
+			line(y-float64(desc.Ascent)/1000*f.fontSize-t-off, -1)
+		}
+	}
+	str := strings.TrimSpace(s.String())
+	if f.decoration.clrSet && str != "" {
+		str = sprintf("q %s %s Q", f.decoration.clr.str, str)
+	}
+	return str
+}
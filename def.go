@@ -54,6 +54,48 @@ func (p PointType) XY() (float64, float64) {
 	return p.X, p.Y
 }
 
+// FontMetricsType contains the metrics of a font, scaled to the font size and
+// expressed in the unit of measure specified in New(). Vertical distances are
+// measured upward from the baseline, so Descent and UnderlinePosition are
+// normally negative. Xmin, Ymin, Xmax and Ymax describe the box that encloses
+// all glyphs of the font when they are drawn at the origin.
+type FontMetricsType struct {
+	Size                   float64 // font size (the em)
+	Ascent                 float64 // height of ascenders such as "d"
+	Descent                float64 // depth of descenders such as "p"
+	CapHeight              float64 // height of flat capital letters such as "H"
+	XHeight                float64 // height of flat lowercase letters such as "x"
+	UnderlinePosition      float64 // distance of the underline from the baseline
+	UnderlineThickness     float64 // thickness of the underline
+	ItalicAngle            float64 // slant of the font in degrees counterclockwise from vertical
+	Xmin, Ymin, Xmax, Ymax float64 // font bounding box
+}
+
+// MeasureType describes the layout of text that has been measured with
+// Measure(). Distances are expressed in the unit of measure specified in
+// New().
+type MeasureType struct {
+	Lines  int     // number of lines that contain cells
+	Height float64 // distance from the starting position to the bottom of the last line
+	X, Y   float64 // current position after the layout
+}
+
+// TabStopType describes a tab stop set with SetTabStops(). Pos is the
+// distance of the stop from the left edge of the cell in MultiCell() and
+// SplitLines(), or from the left margin in Write(), in the unit of measure
+// specified in New(). Align is "L" to begin the text that follows a tab at
+// the stop, "R" to end it there, "C" to center it on the stop or "D" to place
+// its first decimal separator at the stop. Decimal is the separator used by
+// "D" stops; an empty string is replaced with ".". Leader, if not empty, is
+// repeated to fill the space between the preceding text and the text that is
+// aligned at the stop, for example "." for a dot leader.
+type TabStopType struct {
+	Pos     float64
+	Align   string
+	Decimal string
+	Leader  string
+}
+
 // ImageInfoType contains size, color and other information about an image
 type ImageInfoType struct {
 	data  []byte
@@ -97,6 +139,9 @@ func (info *ImageInfoType) Height() float64 {
 
 type fontFileType struct {
 	length1, length2 int64
+	subtype          string // FontFile3 subtype, for example "OpenType"
+	dir              string // directory of the file if not the font directory
+	content          []byte // compressed file content if the font was loaded from memory
 	n                int
 }
 
@@ -163,14 +208,24 @@ type Fpdf struct {
 	coreFonts        map[string]bool           // array of core font names
 	fonts            map[string]fontDefType    // array of used fonts
 	fontFiles        map[string]fontFileType   // array of font files
+	fontRegistry     map[string]fontRegType    // fonts found by RegisterFontDirectory() keyed by family and style
 	diffs            []string                  // array of encoding differences
 	fontFamily       string                    // current font family
 	fontStyle        string                    // current font style
 	underline        bool                      // underlining flag
+	strikeout        bool                      // strikethrough flag
+	overline         bool                      // overlining flag
+	decoration       decorationType            // appearance of underlines, strikethroughs and overlines
 	currentFont      fontDefType               // current font info
 	fontSizePt       float64                   // current font size in points
 	fontSize         float64                   // current font size in user unit
 	ws               float64                   // word spacing
+	kerning          bool                      // apply pair kerning of current font
+	ligatures        bool                      // substitute standard ligatures
+	dligatures       bool                      // substitute discretionary ligatures
+	rtl              bool                      // right-to-left paragraph direction
+	fallbacks        map[string][]string       // fallback font families keyed by primary family
+	textState        textStateType             // text rendering mode, spacing, scaling and rise
 	images           map[string]*ImageInfoType // array of used images
 	pageLinks        [][]linkType              // pageLinks[page][link], both 1-based
 	links            []intLinkType             // array of internal links
@@ -178,7 +233,16 @@ type Fpdf struct {
 	outlineRoot      int                       // root of outlines
 	autoPageBreak    bool                      // automatic page breaking
 	acceptPageBreak  func() bool               // returns true to accept page break
+	hyphenFnc        func([]rune) []int        // returns the positions at which a word can be hyphenated
+	lineBreakMode    string                    // line breaking algorithm: "first-fit" or "total-fit"
+	silent           bool                      // output to the document is suppressed
+	cellOverflow     string                    // treatment of text wider than a cell: "visible", "clip", "ellipsis", "scale" or "shrink"
 	pageBreakTrigger float64                   // threshold used to trigger page breaks
+	measure          *measureStateType         // layout being measured with Measure(), or nil
+	tabStops         []TabStopType             // tab stops sorted by position
+	synth            synthType                 // synthetic styles of the current font
+	columns          columnsType               // columns in which text is set
+	exclusions       map[int][][]PointType     // polygons around which text is wrapped, by page
 	inHeader         bool                      // flag set when processing header
 	headerFnc        func()                    // function provided by app and called to write header
 	inFooter         bool                      // flag set when processing footer
@@ -231,21 +295,79 @@ type fontDescType struct {
 	MissingWidth int
 }
 
+// kernPairsType maps a first and a second character code to the kerning
+// adjustment between them in thousandths of the font size
+type kernPairsType map[int]map[int]int
+
+// Text rendering modes
+const (
+	CnTextRenderFill           = 0 // fill glyphs (default)
+	CnTextRenderStroke         = 1 // stroke glyph outlines
+	CnTextRenderFillStroke     = 2 // fill, then stroke glyphs
+	CnTextRenderInvisible      = 3 // neither fill nor stroke glyphs
+	CnTextRenderFillClip       = 4 // fill glyphs and add to clipping path
+	CnTextRenderStrokeClip     = 5 // stroke glyphs and add to clipping path
+	CnTextRenderFillStrokeClip = 6 // fill, stroke and add to clipping path
+	CnTextRenderClip           = 7 // add glyphs to clipping path
+)
+
+// textStateType holds the text state parameters that are set by the
+// application
+type textStateType struct {
+	render      int     // text rendering mode (Tr)
+	charSpacing float64 // character spacing in user units (Tc)
+	wordSpacing float64 // word spacing in user units (Tw)
+	scale       float64 // horizontal scaling in percent (Tz)
+	rise        float64 // baseline rise in user units (Ts)
+}
+
+// synthType holds the state of synthetic font styles
+type synthType struct {
+	on        bool // missing bold and italic styles are emulated
+	bold      bool // current font is emboldened by stroking
+	italic    bool // current font is slanted with the text matrix
+	smallCaps bool // lowercase letters are replaced with scaled capitals
+}
+
+type columnsType struct {
+	widths   []float64 // widths of the columns, none if text is not set in columns
+	gutter   float64   // space between adjacent columns
+	left     float64   // left edge of the first column
+	col      int       // index of the current column
+	top      float64   // top of the columns on the current page
+	bottom   float64   // lowest position reached by a column that has been left
+	lMargin  float64   // left margin of the page
+	rMargin  float64   // right margin of the page
+	trial    bool      // columns are being balanced with output suppressed
+	overflow bool      // text of a trial layout did not fit in the last column
+}
+
+type decorationType struct {
+	styleStr  string  // "solid", "double" or "dashed"
+	thickness float64 // line thickness in user units; zero for that of the font
+	offset    float64 // displacement from the normal position in user units
+	clrSet    bool    // clr is used rather than the text color
+	clr       clrType
+}
+
 type fontDefType struct {
-	Tp           string       // "Core", "TrueType", ...
-	Name         string       // "Courier-Bold", ...
-	Desc         fontDescType // Font descriptor
-	Up           int          // Underline position
-	Ut           int          // Underline thickness
-	Cw           [256]int     // Character width by ordinal
-	Enc          string       // "cp1252", ...
-	Diff         string       // Differences from reference encoding
-	File         string       // "Redressed.z"
-	Size1, Size2 int          // Type1 values
-	OriginalSize int          // Size of uncompressed font file
-	I            int          // 1-based position in font list, set by font loader, not this program
-	N            int          // Set by font loader
-	DiffN        int          // Position of diff in app array, set by font loader
+	Tp           string        // "Core", "TrueType", ...
+	Name         string        // "Courier-Bold", ...
+	Desc         fontDescType  // Font descriptor
+	Up           int           // Underline position
+	Ut           int           // Underline thickness
+	Cw           [256]int      // Character width by ordinal
+	Enc          string        // "cp1252", ...
+	Diff         string        // Differences from reference encoding
+	File         string        // "Redressed.z"
+	Size1, Size2 int           // Type1 values
+	OriginalSize int           // Size of uncompressed font file
+	Kp           kernPairsType `json:",omitempty"` // Kerning pairs
+	I            int           // 1-based position in font list, set by font loader, not this program
+	N            int           // Set by font loader
+	DiffN        int           // Position of diff in app array, set by font loader
+	utf8         *utf8FontType // Unicode font data, nil for single-byte fonts
+	cid          *cidFontType  // standard CJK font data, nil for other fonts
 }
 
 type fontInfoType struct {
@@ -253,11 +375,13 @@ type fontInfoType struct {
 	File               string
 	OriginalSize       int
 	FontName           string
+	CFF                bool
 	Bold               bool
 	IsFixedPitch       bool
 	UnderlineThickness int
 	UnderlinePosition  int
 	Widths             [256]int
+	Kp                 kernPairsType
 	Size1, Size2       uint32
 	Desc               fontDescType
 }
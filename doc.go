@@ -34,7 +34,51 @@ Features
 
 • Internal and external links
 
-• TrueType, Type1 and encoding support
+• TrueType, OpenType, Type1 and encoding support
+
+• Unicode TrueType fonts with UTF-8 text, font subsetting and font fallback
+
+• Pair kerning and ligatures
+
+• Right-to-left text (Arabic and Hebrew) with bidirectional reordering
+
+• Text rendering modes, character and word spacing, scaling and rise
+
+• Automatic hyphenation with TeX patterns
+
+• Total-fit (Knuth-Plass) line breaking of paragraphs
+
+• Rich text paragraphs of runs with different fonts, sizes, colors and links
+
+• Text boxes that reduce the font size until the text fits
+
+• Cell overflow handling by clipping, ellipsis, scaling or shrinking
+
+• Standard Chinese, Japanese and Korean fonts without embedding, including vertical writing
+
+• Font metrics and ink bounding boxes of text
+
+• Strikethrough, overline, double and dashed text decorations
+
+• Registration of font directories by family and style
+
+• Fonts loaded from memory without font definition files
+
+• Fonts compiled into applications as Go source generated by makefont
+
+• Text drawn as vector outlines or used as a clipping path
+
+• Measurement of the layout of text without output
+
+• Tab stops with left, right, centered and decimal alignment and leaders
+
+• Synthetic bold and italic styles for fonts without those variants, and small capitals
+
+• Text along paths of lines, arcs and Bézier curves, including SVG textPath elements
+
+• Text flow through multiple columns with balanced column heights
+
+• Text wrapped around images and shapes with rectangular and polygonal exclusion regions
 
 • Page compression
 
@@ -49,8 +93,8 @@ Features
 • Layers
 
 gofpdf has no dependencies other than the Go standard library. All tests pass
-on Linux, Mac and Windows platforms. Like FPDF version 1.7, from which gofpdf
-is derived, this package does not yet support UTF-8 fonts. However, support is
+on Linux, Mac and Windows platforms. UTF-8 text can be used directly with
+TrueType fonts that are loaded with AddUTF8Font(). For other fonts, support is
 provided to translate UTF-8 runes to code page encodings.
 
 Acknowledgments
@@ -151,26 +195,36 @@ Nonstandard Fonts
 Nothing special is required to use the standard PDF fonts (courier, helvetica,
 times, zapfdingbats) in your documents other than calling SetFont().
 
-In order to use a different TrueType or Type1 font, you will need to generate a
-font definition file and, if the font will be embedded into PDFs, a compressed
-version of the font file. This is done by calling the MakeFont function or
-using the included makefont command line utility. To create the utility, cd
-into the makefont subdirectory and run "go build". This will produce a
-standalone executable named makefont. Select the appropriate encoding file from
-the font subdirectory and run the command as in the following example.
+In order to use a different TrueType, OpenType or Type1 font, you will need to
+generate a font definition file and, if the font will be embedded into PDFs, a
+compressed version of the font file. This is done by calling the MakeFont
+function or using the included makefont command line utility. To create the
+utility, cd into the makefont subdirectory and run "go build". This will
+produce a standalone executable named makefont. Select the appropriate encoding
+file from the font subdirectory and run the command as in the following
+example.
 
 	./makefont --embed --enc=../font/cp1252.map --dst=../font ../font/calligra.ttf
 
 In your PDF generation code, call AddFont() to load the font and, as with the
 standard fonts, SetFont() to begin using it. See tutorial 7 for an example.
+
+To compile the font into your application instead, add the --pkg option with
+the name of the package in which to generate a Go source file. The file
+declares a function, such as AddCalligraFont() for calligra.ttf, that loads
+the font without reading any files. See tutorial 46 and MakeFontSource().
+
+A TrueType font can also be used without a font definition file by calling
+AddUTF8Font(). In this case the font file is read directly, text is passed to
+the text functions as UTF-8, and only the glyphs that are actually used in the
+document are embedded. See tutorial 29 for an example. OpenType fonts with
+PostScript outlines can be used in either way; see tutorial 30.
+
 Good sources of free, open-source fonts include http://www.google.com/fonts/
 and http://dejavu-fonts.org/.
 
 Roadmap
 
-• Handle UTF-8 source text natively. Until then, automatic translation of
-UTF-8 runes to code page bytes is provided.
-
 • Improve test coverage as reported by the coverage tool.
 
 */
@@ -0,0 +1,161 @@
+package gofpdf
+
+// Regions of a page around which text is wrapped
+
+import (
+	"fmt"
+	"math"
+)
+
+// AddExclusionRect adds a rectangular exclusion region to the current page.
+// (x, y) is the upper left corner of the rectangle and w and h are its width
+// and height, in the unit of measure specified in New(). See
+// AddExclusionPolygon() for the effect of exclusion regions.
+//
+// See tutorial 53 for an example of this function.
+func (f *Fpdf) AddExclusionRect(x, y, w, h float64) {
+	f.AddExclusionPolygon([]PointType{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}})
+}
+
+// AddExclusionPolygon adds an exclusion region bounded by the polygon with
+// the vertices in points, in the unit of measure specified in New(), to the
+// current page. The region is typically placed over an image or a shape
+// drawn with Image(), Rect() or Polygon() so that text wraps around it.
+//
+// MultiCell(), Write() and the Write() method of HTMLBasicType keep each line
+// of text out of the exclusion regions that it overlaps vertically. The line
+// is placed in the widest part of its width that remains, beside the
+// regions; if no part is at least as wide as the font size plus the cell
+// margins, the line is skipped and the text continues on the next line. A
+// region occupies the full horizontal extent of the polygon at the height of
+// each line, so concave shapes are treated as if their hollows were filled.
+// While exclusion regions are set on the current page, MultiCell() breaks
+// paragraphs with the first-fit algorithm (see SetLineBreakMode()). Borders
+// and fill of MultiCell() are drawn for each line where it is placed. Other
+// output, including Cell() and Text(), is not affected.
+//
+// Exclusion regions remain in effect on their page until they are removed
+// with ClearExclusions().
+//
+// See tutorial 53 for an example of this function.
+func (f *Fpdf) AddExclusionPolygon(points []PointType) {
+	if f.err != nil {
+		return
+	}
+	if f.page == 0 {
+		f.err = fmt.Errorf("exclusion regions require a page")
+		return
+	}
+	if len(points) < 3 {
+		f.err = fmt.Errorf("exclusion polygon requires at least three points")
+		return
+	}
+	f.exclusions[f.page] = append(f.exclusions[f.page], append([]PointType(nil), points...))
+}
+
+// ClearExclusions removes the exclusion regions of the current page that were
+// added with AddExclusionRect() and AddExclusionPolygon().
+//
+// See tutorial 53 for an example of this function.
+func (f *Fpdf) ClearExclusions() {
+	delete(f.exclusions, f.page)
+}
+
+// flowing returns true if the lines of MultiCell() and Write() can change in
+// position and width because text is set in columns or exclusion regions are
+// set on the current page
+func (f *Fpdf) flowing() bool {
+	return len(f.columns.widths) > 0 || len(f.exclusions[f.page]) > 0
+}
+
+// lineStart begins a line of height h of text that is laid out by MultiCell()
+// or Write() at the distance off from the left margin. The line is w wide, or
+// reaches to the right margin if w is 0, less the parts that are covered by
+// exclusion regions. Column and page breaks that the line would cause are
+// performed first, and lines that are covered are skipped. The current
+// position is set to the beginning of the line and its width is returned.
+func (f *Fpdf) lineStart(off, w, h float64) float64 {
+	for {
+		f.autoBreak(h)
+		x, wd := f.lMargin+off, w
+		if wd == 0 {
+			wd = f.w - f.rMargin - x
+		}
+		// A line that is still covered below the page is not skipped
+		x, wd, ok := f.lineSpan(x, wd, h)
+		if ok || h <= 0 || f.y > f.h {
+			f.x = x
+			return wd
+		}
+		f.y += h
+	}
+}
+
+// lineSpan returns the widest part of the line from x to x+w, of height h at
+// the current vertical position, that is not covered by the exclusion
+// regions of the current page. ok is false if the line is covered and the
+// part is narrower than the font size plus the cell margins.
+func (f *Fpdf) lineSpan(x, w, h float64) (spanX, spanW float64, ok bool) {
+	spans := [][2]float64{{x, x + w}}
+	covered := false
+	for _, poly := range f.exclusions[f.page] {
+		lo, hi, found := polygonBand(poly, f.y, f.y+h)
+		if !found {
+			continue
+		}
+		var rest [][2]float64
+		for _, sp := range spans {
+			if lo > sp[0] {
+				rest = append(rest, [2]float64{sp[0], math.Min(sp[1], lo)})
+			}
+			if hi < sp[1] {
+				rest = append(rest, [2]float64{math.Max(sp[0], hi), sp[1]})
+			}
+		}
+		spans = rest
+		covered = true
+	}
+	if !covered {
+		return x, w, true
+	}
+	spanX = x
+	for _, sp := range spans {
+		if sp[1]-sp[0] > spanW {
+			spanX, spanW = sp[0], sp[1]-sp[0]
+		}
+	}
+	return spanX, spanW, spanW >= f.fontSize+2*f.cMargin
+}
+
+// polygonBand returns the horizontal extent of the part of the polygon poly
+// that lies between the vertical positions y0 and y1. found is false if the
+// polygon does not overlap the band.
+func polygonBand(poly []PointType, y0, y1 float64) (lo, hi float64, found bool) {
+	extend := func(x float64) {
+		if !found || x < lo {
+			lo = x
+		}
+		if !found || x > hi {
+			hi = x
+		}
+		found = true
+	}
+	for j, p := range poly {
+		q := poly[(j+1)%len(poly)]
+		if math.Max(p.Y, q.Y) <= y0 || math.Min(p.Y, q.Y) >= y1 {
+			continue
+		}
+		if p.Y == q.Y {
+			extend(p.X)
+			extend(q.X)
+			continue
+		}
+		// Clip the edge to the band
+		t0, t1 := (y0-p.Y)/(q.Y-p.Y), (y1-p.Y)/(q.Y-p.Y)
+		for _, t := range []float64{t0, t1} {
+			t = math.Max(0, math.Min(1, t))
+			extend(p.X + t*(q.X-p.X))
+		}
+	}
+	return
+}
@@ -25,6 +25,7 @@ package gofpdf
 
 import (
 	"bufio"
+	"bytes"
 	"compress/zlib"
 	"encoding/binary"
 	"encoding/json"
@@ -35,6 +36,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 func baseNoExt(fileStr string) string {
@@ -53,54 +55,48 @@ func loadMap(encodingFileStr string) (encList encListType, err error) {
 	f, err = os.Open(encodingFileStr)
 	if err == nil {
 		defer f.Close()
-		for j := range encList {
-			encList[j].uv = -1
-			encList[j].name = ".notdef"
+		encList, err = loadMapFromReader(f)
+	}
+	return
+}
+
+// loadMapFromReader reads an encoding map such as cp1252.map
+func loadMapFromReader(r io.Reader) (encList encListType, err error) {
+	for j := range encList {
+		encList[j].uv = -1
+		encList[j].name = ".notdef"
+	}
+	scanner := bufio.NewScanner(r)
+	var enc encType
+	var pos int
+	for scanner.Scan() {
+		lineStr := strings.TrimSpace(scanner.Text())
+		if lineStr == "" {
+			continue
 		}
-		scanner := bufio.NewScanner(f)
-		var enc encType
-		var pos int
-		for scanner.Scan() {
-			// "!3F U+003F question"
-			_, err = fmt.Sscanf(scanner.Text(), "!%x U+%x %s", &pos, &enc.uv, &enc.name)
-			if err == nil {
-				if pos < 256 {
-					encList[pos] = enc
-				} else {
-					err = fmt.Errorf("map position 0x%2X exceeds 0xFF", pos)
-					return
-				}
+		// "!3F U+003F question"
+		_, err = fmt.Sscanf(lineStr, "!%x U+%x %s", &pos, &enc.uv, &enc.name)
+		if err == nil {
+			if pos < 256 {
+				encList[pos] = enc
 			} else {
+				err = fmt.Errorf("map position 0x%2X exceeds 0xFF", pos)
 				return
 			}
-		}
-		if err = scanner.Err(); err != nil {
+		} else {
 			return
 		}
 	}
+	err = scanner.Err()
 	return
 }
 
-// Return informations from a TrueType font
-func getInfoFromTrueType(fileStr string, msgWriter io.Writer, embed bool, encList encListType) (info fontInfoType, err error) {
-	var ttf TtfType
-	ttf, err = TtfParse(fileStr)
-	if err != nil {
-		return
-	}
-	if embed {
-		if !ttf.Embeddable {
-			err = fmt.Errorf("font license does not allow embedding")
-			return
-		}
-		info.Data, err = ioutil.ReadFile(fileStr)
-		if err != nil {
-			return
-		}
-		info.OriginalSize = len(info.Data)
-	}
+// Return the encoding-independent metrics of a parsed TrueType font, scaled to
+// 1000 units per em
+func getInfoFromTtf(ttf TtfType) (info fontInfoType) {
 	k := 1000.0 / float64(ttf.UnitsPerEm)
 	info.FontName = ttf.PostScriptName
+	info.CFF = ttf.CFF
 	info.Bold = ttf.Bold
 	info.Desc.ItalicAngle = int(ttf.ItalicAngle)
 	info.IsFixedPitch = ttf.IsFixedPitch
@@ -114,10 +110,41 @@ func getInfoFromTrueType(fileStr string, msgWriter io.Writer, embed bool, encLis
 		round(k * float64(ttf.Xmax)),
 		round(k * float64(ttf.Ymax)),
 	}
-	// printf("FontBBox\n")
-	// dump(info.Desc.FontBBox)
 	info.Desc.CapHeight = round(k * float64(ttf.CapHeight))
 	info.Desc.MissingWidth = round(k * float64(ttf.Widths[0]))
+	return
+}
+
+// Return informations from a TrueType font
+func getInfoFromTrueType(fileStr string, msgWriter io.Writer, embed bool, encList encListType) (info fontInfoType, err error) {
+	var buf []byte
+	buf, err = ioutil.ReadFile(fileStr)
+	if err != nil {
+		return
+	}
+	return getInfoFromTrueTypeBytes(buf, msgWriter, embed, encList)
+}
+
+// Return informations from a TrueType font that has been loaded into memory
+func getInfoFromTrueTypeBytes(buf []byte, msgWriter io.Writer, embed bool, encList encListType) (info fontInfoType, err error) {
+	var ttf TtfType
+	ttf, err = ttfParseBytes(buf)
+	if err != nil {
+		return
+	}
+	if embed {
+		if !ttf.Embeddable {
+			err = fmt.Errorf("font license does not allow embedding")
+			return
+		}
+		info.Data = buf
+		info.OriginalSize = len(info.Data)
+	}
+	data := info.Data
+	info = getInfoFromTtf(ttf)
+	info.Data = data
+	info.OriginalSize = len(data)
+	k := 1000.0 / float64(ttf.UnitsPerEm)
 	var wd int
 	for j := 0; j < len(info.Widths); j++ {
 		wd = info.Desc.MissingWidth
@@ -132,6 +159,30 @@ func getInfoFromTrueType(fileStr string, msgWriter io.Writer, embed bool, encLis
 		}
 		info.Widths[j] = wd
 	}
+	// Kerning pairs of the encoded characters
+	codes := make(map[uint16][]int)
+	for j := range encList {
+		if encList[j].name != ".notdef" {
+			if pos, ok := ttf.Chars[uint16(encList[j].uv)]; ok {
+				codes[pos] = append(codes[pos], j)
+			}
+		}
+	}
+	for left, pairs := range ttf.Kerning {
+		for _, c1 := range codes[left] {
+			for right, val := range pairs {
+				for _, c2 := range codes[right] {
+					if info.Kp == nil {
+						info.Kp = make(kernPairsType)
+					}
+					if info.Kp[c1] == nil {
+						info.Kp[c1] = make(map[int]int)
+					}
+					info.Kp[c1][c2] = round(k * float64(val))
+				}
+			}
+		}
+	}
 	// printf("getInfoFromTrueType/FontBBox\n")
 	// dump(info.Desc.FontBBox)
 	return
@@ -311,11 +362,7 @@ func makeFontDescriptor(info *fontInfoType) {
 }
 
 // Build differences from reference encoding
-func makeFontEncoding(encList encListType, refEncFileStr string) (diffStr string, err error) {
-	var refList encListType
-	if refList, err = loadMap(refEncFileStr); err != nil {
-		return
-	}
+func makeFontEncoding(encList, refList encListType) (diffStr string) {
 	var buf fmtBuffer
 	last := 0
 	for j := 32; j < 256; j++ {
@@ -327,12 +374,13 @@ func makeFontEncoding(encList encListType, refEncFileStr string) (diffStr string
 			buf.printf("/%s ", encList[j].name)
 		}
 	}
-	diffStr = strings.TrimSpace(buf.String())
-	return
+	return strings.TrimSpace(buf.String())
 }
 
-func makeDefinitionFile(fileStr, tpStr, encodingFileStr string, embed bool, encList encListType, info fontInfoType) (err error) {
-	var def fontDefType
+// makeFontDef returns the definition of a font with the encoding encStr
+// described by encList. refList is the cp1252 encoding with respect to which
+// differences are recorded.
+func makeFontDef(tpStr, encStr string, encList, refList encListType, info fontInfoType) (def fontDefType) {
 	def.Tp = tpStr
 	def.Name = info.FontName
 	makeFontDescriptor(&info)
@@ -342,23 +390,34 @@ func makeDefinitionFile(fileStr, tpStr, encodingFileStr string, embed bool, encL
 	def.Up = info.UnderlinePosition
 	def.Ut = info.UnderlineThickness
 	def.Cw = info.Widths
-	def.Enc = baseNoExt(encodingFileStr)
-	// fmt.Printf("encodingFileStr [%s], def.Enc [%s]\n", encodingFileStr, def.Enc)
-	// fmt.Printf("reference [%s]\n", filepath.Join(filepath.Dir(encodingFileStr), "cp1252.map"))
-	def.Diff, err = makeFontEncoding(encList, filepath.Join(filepath.Dir(encodingFileStr), "cp1252.map"))
-	if err != nil {
-		return
-	}
+	def.Enc = encStr
+	def.Diff = makeFontEncoding(encList, refList)
 	def.File = info.File
 	def.Size1 = int(info.Size1)
 	def.Size2 = int(info.Size2)
 	def.OriginalSize = info.OriginalSize
-	// printf("Font definition file [%s]\n", fileStr)
+	def.Kp = info.Kp
+	return
+}
+
+// makeDefinition returns the JSON font definition of a font with the
+// encoding of encodingFileStr
+func makeDefinition(tpStr, encodingFileStr string, encList encListType, info fontInfoType) (buf []byte, err error) {
+	var refList encListType
+	if refList, err = loadMap(filepath.Join(filepath.Dir(encodingFileStr), "cp1252.map")); err != nil {
+		return
+	}
+	def := makeFontDef(tpStr, baseNoExt(encodingFileStr), encList, refList, info)
+	return json.Marshal(def)
+}
+
+func makeDefinitionFile(fileStr, tpStr, encodingFileStr string, embed bool, encList encListType, info fontInfoType) (err error) {
 	var buf []byte
-	buf, err = json.Marshal(def)
+	buf, err = makeDefinition(tpStr, encodingFileStr, encList, info)
 	if err != nil {
 		return
 	}
+	// printf("Font definition file [%s]\n", fileStr)
 	var f *os.File
 	f, err = os.Create(fileStr)
 	if err != nil {
@@ -374,8 +433,10 @@ func makeDefinitionFile(fileStr, tpStr, encodingFileStr string, embed bool, encL
 // gofpdf generates. See the makefont utility in the gofpdf package for a
 // command line interface to this function.
 //
-// fontFileStr is the name of the TrueType (or OpenType based on TrueType) or
-// Type1 file from which to generate a definition file.
+// fontFileStr is the name of the TrueType (.ttf), OpenType (.otf) or binary
+// Type1 (.pfb) file from which to generate a definition file. OpenType fonts
+// may contain either TrueType or PostScript (CFF) outlines; the latter are
+// embedded as OpenType font programs, which requires PDF version 1.6.
 //
 // encodingFileStr is the name of the encoding file that corresponds to the
 // font.
@@ -391,13 +452,158 @@ func MakeFont(fontFileStr, encodingFileStr, dstDirStr string, msgWriter io.Write
 	if msgWriter == nil {
 		msgWriter = ioutil.Discard
 	}
+	var tpStr string
+	var encList encListType
+	var info fontInfoType
+	tpStr, encList, info, err = makeFontInfo(fontFileStr, encodingFileStr, msgWriter, embed)
+	if err != nil {
+		return
+	}
+	baseStr := baseNoExt(fontFileStr)
+	// fmt.Printf("Base [%s]\n", baseStr)
+	if embed {
+		var f *os.File
+		info.File = baseStr + ".z"
+		zFileStr := filepath.Join(dstDirStr, info.File)
+		f, err = os.Create(zFileStr)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		cmp := zlib.NewWriter(f)
+		cmp.Write(info.Data)
+		cmp.Close()
+		fmt.Fprintf(msgWriter, "Font file compressed: %s\n", zFileStr)
+	}
+	defFileStr := filepath.Join(dstDirStr, baseStr+".json")
+	err = makeDefinitionFile(defFileStr, tpStr, encodingFileStr, embed, encList, info)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(msgWriter, "Font definition file successfully generated: %s\n", defFileStr)
+	return
+}
+
+// MakeFontSource generates a Go source file that contains a font definition
+// and, if embed is true, the compressed font file, so that the font can be
+// compiled into an application and used without access to any files at run
+// time. See the makefont utility in the gofpdf package for a command line
+// interface to this function.
+//
+// fontFileStr, encodingFileStr, msgWriter and embed are as in MakeFont(). The
+// file is saved in the directory dstDirStr with the base name of the font file
+// and the extension ".go". pkgStr is the name of its package.
+//
+// The file declares a function that makes the font available in a document.
+// Its name is derived from the name of the font file; for example, the font
+// file "DejaVuSans-Bold.ttf" results in
+//
+//	func AddDejaVuSansBoldFont(pdf *gofpdf.Fpdf, familyStr, styleStr string)
+//
+// where familyStr and styleStr are as in AddFont(). The function calls
+// AddFontFromDefinition().
+func MakeFontSource(fontFileStr, encodingFileStr, dstDirStr, pkgStr string, msgWriter io.Writer, embed bool) (err error) {
+	if msgWriter == nil {
+		msgWriter = ioutil.Discard
+	}
+	var tpStr string
+	var encList encListType
+	var info fontInfoType
+	tpStr, encList, info, err = makeFontInfo(fontFileStr, encodingFileStr, msgWriter, embed)
+	if err != nil {
+		return
+	}
+	baseStr := baseNoExt(fontFileStr)
+	nameStr := filepath.Base(fontFileStr)
+	if embed {
+		info.File = baseStr + ".z"
+	}
+	var def []byte
+	def, err = makeDefinition(tpStr, encodingFileStr, encList, info)
+	if err != nil {
+		return
+	}
+	identStr := goIdentifier(baseStr)
+	runes := []rune(identStr)
+	runes[0] = unicode.ToLower(runes[0])
+	varStr := string(runes)
+	var buf fmtBuffer
+	buf.printf("// Code generated by makefont from %s; DO NOT EDIT.\n\n", nameStr)
+	buf.printf("package %s\n\n", pkgStr)
+	buf.printf("import \"github.com/jung-kurt/gofpdf\"\n\n")
+	buf.printf("// %sDefinition is the font definition of %s with the encoding %s\n",
+		varStr, nameStr, baseNoExt(encodingFileStr))
+	if strings.Contains(string(def), "`") {
+		buf.printf("var %sDefinition = []byte(%s)\n\n", varStr, strconv.Quote(string(def)))
+	} else {
+		buf.printf("var %sDefinition = []byte(`%s`)\n\n", varStr, def)
+	}
+	fontStr := "nil"
+	if embed {
+		buf.printf("// %sFont is the compressed font file %s\n", varStr, nameStr)
+		buf.printf("var %sFont = []byte(\"%s\")\n\n", varStr, goEscape(sliceCompress(info.Data)))
+		fontStr = varStr + "Font"
+	}
+	buf.printf("// Add%sFont makes the font %s available in pdf with the family\n", identStr, nameStr)
+	buf.printf("// familyStr and the style styleStr. See gofpdf.AddFont() for details.\n")
+	buf.printf("func Add%sFont(pdf *gofpdf.Fpdf, familyStr, styleStr string) {\n", identStr)
+	buf.printf("\tpdf.AddFontFromDefinition(familyStr, styleStr, %sDefinition, %s)\n}\n", varStr, fontStr)
+	goFileStr := filepath.Join(dstDirStr, baseStr+".go")
+	err = ioutil.WriteFile(goFileStr, buf.Bytes(), 0644)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(msgWriter, "Font source file successfully generated: %s\n", goFileStr)
+	return
+}
+
+// goIdentifier returns an exported Go identifier made from the letters and
+// digits of str
+func goIdentifier(str string) string {
+	var buf bytes.Buffer
+	upper := true
+	for _, r := range str {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if buf.Len() == 0 && unicode.IsDigit(r) {
+				buf.WriteString("Font")
+			}
+			if upper {
+				r = unicode.ToUpper(r)
+			}
+			buf.WriteRune(r)
+			upper = false
+		} else {
+			upper = true
+		}
+	}
+	if buf.Len() == 0 {
+		return "Font"
+	}
+	return buf.String()
+}
+
+// goEscape returns the contents of an interpreted Go string literal that
+// represents data
+func goEscape(data []byte) string {
+	var buf bytes.Buffer
+	for _, b := range data {
+		if b >= ' ' && b < 0x7f && b != '"' && b != '\\' {
+			buf.WriteByte(b)
+		} else {
+			fmt.Fprintf(&buf, "\\x%02x", b)
+		}
+	}
+	return buf.String()
+}
+
+// makeFontInfo returns the type, encoding and information of a font file
+func makeFontInfo(fontFileStr, encodingFileStr string, msgWriter io.Writer, embed bool) (tpStr string, encList encListType, info fontInfoType, err error) {
 	if !fileExist(fontFileStr) {
 		err = fmt.Errorf("font file not found: %s", fontFileStr)
 		return
 	}
 	extStr := strings.ToLower(fontFileStr[len(fontFileStr)-3:])
 	// printf("Font file extension [%s]\n", extStr)
-	var tpStr string
 	if extStr == "ttf" || extStr == "otf" {
 		tpStr = "TrueType"
 	} else if extStr == "pfb" {
@@ -406,8 +612,6 @@ func MakeFont(fontFileStr, encodingFileStr, dstDirStr string, msgWriter io.Write
 		err = fmt.Errorf("unrecognized font file extension: %s", extStr)
 		return
 	}
-	var encList encListType
-	var info fontInfoType
 	encList, err = loadMap(encodingFileStr)
 	if err != nil {
 		return
@@ -419,33 +623,14 @@ func MakeFont(fontFileStr, encodingFileStr, dstDirStr string, msgWriter io.Write
 		if err != nil {
 			return
 		}
+		if info.CFF {
+			tpStr = "OpenType"
+		}
 	} else {
 		info, err = getInfoFromType1(fontFileStr, msgWriter, embed, encList)
 		if err != nil {
 			return
 		}
 	}
-	baseStr := baseNoExt(fontFileStr)
-	// fmt.Printf("Base [%s]\n", baseStr)
-	if embed {
-		var f *os.File
-		info.File = baseStr + ".z"
-		zFileStr := filepath.Join(dstDirStr, info.File)
-		f, err = os.Create(zFileStr)
-		if err != nil {
-			return
-		}
-		defer f.Close()
-		cmp := zlib.NewWriter(f)
-		cmp.Write(info.Data)
-		cmp.Close()
-		fmt.Fprintf(msgWriter, "Font file compressed: %s\n", zFileStr)
-	}
-	defFileStr := filepath.Join(dstDirStr, baseStr+".json")
-	err = makeDefinitionFile(defFileStr, tpStr, encodingFileStr, embed, encList, info)
-	if err != nil {
-		return
-	}
-	fmt.Fprintf(msgWriter, "Font definition file successfully generated: %s\n", defFileStr)
 	return
 }
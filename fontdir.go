@@ -0,0 +1,213 @@
+package gofpdf
+
+// Registration of the fonts in a directory by family and style
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// fontRegType is a font found by RegisterFontDirectory()
+type fontRegType struct {
+	familyStr string // family name as given by the font
+	styleStr  string // "", "B", "I" or "BI"
+	fileStr   string // path of the font file or font definition file
+	utf8      bool   // loaded with AddUTF8Font() rather than AddFont()
+}
+
+// registryFamily returns the key of a family in the font registry; case and
+// spaces are ignored so that, for example, "Open Sans" matches "OpenSans"
+func registryFamily(familyStr string) string {
+	return strings.ToLower(strings.Replace(familyStr, " ", "", -1))
+}
+
+// RegisterFontDirectory examines the TrueType (.ttf) and OpenType (.otf) font
+// files and the font definition files (.json) generated by the makefont
+// utility in the directory dirStr, and groups the fonts by family and style so
+// that they can be selected with SetFont() without first calling AddFont() or
+// AddUTF8Font() for each of them. Fonts are loaded when they are first
+// selected. An empty dirStr indicates the font directory specified in the call
+// to New() or SetFontLocation().
+//
+// The family and style of a font file are taken from its name and OS/2
+// tables; font files are loaded as with AddUTF8Font(), so that text is
+// interpreted as UTF-8. The family and style of a font definition file are
+// derived from the PostScript name and descriptor of the font, and the font is
+// loaded as with AddFont(). Family names are matched without regard to case
+// or spaces, so that a font named "OpenSans-BoldItalic" is selected with
+// SetFont("Open Sans", "BI", 12). Definitions of the core fonts are ignored
+// and the core families cannot be replaced in this way; use AddFont() to
+// override them.
+//
+// A family and style that is already registered is kept, except that a font
+// file takes the place of a font definition file. Files that cannot be parsed
+// are skipped. An error is set only if the directory cannot be read.
+//
+// See tutorial 44 for an example of this function.
+func (f *Fpdf) RegisterFontDirectory(dirStr string) {
+	if f.err != nil {
+		return
+	}
+	if dirStr == "" {
+		dirStr = f.fontpath
+	}
+	list, err := ioutil.ReadDir(dirStr)
+	if err != nil {
+		f.err = err
+		return
+	}
+	for _, info := range list {
+		if info.IsDir() {
+			continue
+		}
+		fileStr := filepath.Join(dirStr, info.Name())
+		var reg fontRegType
+		var ok bool
+		switch strings.ToLower(filepath.Ext(fileStr)) {
+		case ".ttf", ".otf":
+			reg, ok = registeredFontFile(fileStr)
+		case ".json":
+			reg, ok = registeredFontDef(fileStr)
+		}
+		if !ok {
+			continue
+		}
+		key := registryFamily(reg.familyStr) + reg.styleStr
+		if prev, found := f.fontRegistry[key]; found && (prev.utf8 || !reg.utf8) {
+			continue
+		}
+		f.fontRegistry[key] = reg
+	}
+}
+
+// registeredFontFile returns the family and style of a TrueType or OpenType
+// font file
+func registeredFontFile(fileStr string) (reg fontRegType, ok bool) {
+	ttf, err := TtfParse(fileStr)
+	if err != nil || !ttf.Embeddable {
+		return
+	}
+	reg.familyStr = ttf.FamilyName
+	if reg.familyStr == "" {
+		reg.familyStr = familyFromPostScriptName(ttf.PostScriptName)
+	}
+	if ttf.Bold {
+		reg.styleStr += "B"
+	}
+	if ttf.Italic {
+		reg.styleStr += "I"
+	}
+	reg.fileStr = fileStr
+	reg.utf8 = true
+	return reg, reg.familyStr != ""
+}
+
+// registeredFontDef returns the family and style of a font definition file
+func registeredFontDef(fileStr string) (reg fontRegType, ok bool) {
+	buf, err := ioutil.ReadFile(fileStr)
+	if err != nil {
+		return
+	}
+	var def fontDefType
+	if json.Unmarshal(buf, &def) != nil || def.Tp == "" || def.Tp == "Core" {
+		return
+	}
+	reg.familyStr = familyFromPostScriptName(def.Name)
+	nameStr := strings.ToLower(def.Name)
+	if def.Desc.StemV >= 120 || strings.Contains(nameStr, "bold") {
+		reg.styleStr += "B"
+	}
+	if def.Desc.Flags&(1<<6) != 0 || strings.Contains(nameStr, "italic") ||
+		strings.Contains(nameStr, "oblique") {
+		reg.styleStr += "I"
+	}
+	reg.fileStr = fileStr
+	return reg, reg.familyStr != ""
+}
+
+// familyFromPostScriptName returns the family part of a PostScript font name
+// such as "OpenSans-BoldItalic", "Arial,Bold" or "CalligrapherRegular"
+func familyFromPostScriptName(nameStr string) string {
+	if pos := strings.IndexAny(nameStr, "-,"); pos >= 0 {
+		return nameStr[:pos]
+	}
+	for _, suffix := range []string{"BoldItalic", "BoldOblique", "Bold", "Italic",
+		"Oblique", "Regular", "Roman", "Book"} {
+		if strings.HasSuffix(nameStr, suffix) && len(nameStr) > len(suffix) {
+			return nameStr[:len(nameStr)-len(suffix)]
+		}
+	}
+	return nameStr
+}
+
+// addRegisteredFont loads the registered font reg as familyStr and styleStr
+func (f *Fpdf) addRegisteredFont(familyStr, styleStr string, reg fontRegType) {
+	if reg.utf8 {
+		buf, err := ioutil.ReadFile(reg.fileStr)
+		if err != nil {
+			f.err = err
+			return
+		}
+		f.AddUTF8FontFromBytes(familyStr, styleStr, buf)
+		return
+	}
+	file, err := os.Open(reg.fileStr)
+	if err != nil {
+		f.err = err
+		return
+	}
+	defer file.Close()
+	f.AddFontFromReader(familyStr, styleStr, file)
+	if f.err != nil {
+		return
+	}
+	// The embedded font file is read from the directory of the definition
+	// rather than from the font directory
+	embedStr := f.fonts[strings.ToLower(familyStr)+styleStr].File
+	if info, ok := f.fontFiles[embedStr]; ok && embedStr != "" {
+		info.dir = filepath.Dir(reg.fileStr)
+		f.fontFiles[embedStr] = info
+	}
+}
+
+// RegisteredFontFamilies returns the names of the font families found by
+// RegisterFontDirectory(), sorted without regard to case or spaces.
+//
+// See tutorial 44 for an example of this function.
+func (f *Fpdf) RegisteredFontFamilies() (list []string) {
+	keys := make([]string, 0, len(f.fontRegistry))
+	for key := range f.fontRegistry {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	seen := make(map[string]bool)
+	for _, k := range keys {
+		reg := f.fontRegistry[k]
+		key := registryFamily(reg.familyStr)
+		if !seen[key] {
+			seen[key] = true
+			list = append(list, reg.familyStr)
+		}
+	}
+	return
+}
+
+// RegisteredFontStyles returns the styles of the font family familyStr found
+// by RegisterFontDirectory(), in the order "" (regular), "B" (bold), "I"
+// (italic) and "BI" (bold italic). The family is matched without regard to
+// case or spaces. The list is empty if the family is not registered.
+//
+// See tutorial 44 for an example of this function.
+func (f *Fpdf) RegisteredFontStyles(familyStr string) (list []string) {
+	key := registryFamily(familyStr)
+	for _, styleStr := range []string{"", "B", "I", "BI"} {
+		if _, ok := f.fontRegistry[key+styleStr]; ok {
+			list = append(list, styleStr)
+		}
+	}
+	return
+}
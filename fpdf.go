@@ -38,6 +38,7 @@ import (
 	"path"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 type fmtBuffer struct {
@@ -67,9 +68,11 @@ func fpdfNew(orientationStr, unitStr, sizeStr, fontDirStr string, size SizeType)
 	f.pages = make([]*bytes.Buffer, 0, 8)
 	f.pages = append(f.pages, bytes.NewBufferString("")) // pages[0] is unused (1-based)
 	f.pageSizes = make(map[int]SizeType)
+	f.exclusions = make(map[int][][]PointType)
 	f.state = 0
 	f.fonts = make(map[string]fontDefType)
 	f.fontFiles = make(map[string]fontFileType)
+	f.fontRegistry = make(map[string]fontRegType)
 	f.diffs = make([]string, 0, 8)
 	f.images = make(map[string]*ImageInfoType)
 	f.pageLinks = make([][]linkType, 0, 8)
@@ -83,11 +86,15 @@ func fpdfNew(orientationStr, unitStr, sizeStr, fontDirStr string, size SizeType)
 	f.fontStyle = ""
 	f.SetFontSize(12)
 	f.underline = false
+	f.decoration.styleStr = "solid"
 	f.SetDrawColor(0, 0, 0)
 	f.SetFillColor(0, 0, 0)
 	f.SetTextColor(0, 0, 0)
 	f.colorFlag = false
 	f.ws = 0
+	f.textState.scale = 100
+	f.lineBreakMode = "first-fit"
+	f.cellOverflow = "visible"
 	f.fontpath = fontDirStr
 	// Core fonts
 	f.coreFonts = map[string]bool{
@@ -537,16 +544,18 @@ func (f *Fpdf) AddPageFormat(orientationStr string, size SizeType) {
 		f.open()
 	}
 	familyStr := f.fontFamily
-	style := f.fontStyle
-	if f.underline {
-		style += "U"
-	}
+	style := f.fontStyle + f.decorationStyle()
 	fontsize := f.fontSizePt
 	lw := f.lineWidth
 	dc := f.color.draw
 	fc := f.color.fill
 	tc := f.color.text
 	cf := f.colorFlag
+	ts := f.textState
+	if len(f.columns.widths) > 0 {
+		// Header and footer use the margins of the page
+		f.lMargin, f.rMargin = f.columns.lMargin, f.columns.rMargin
+	}
 	if f.page > 0 {
 		// Page footer
 		if f.footerFnc != nil {
@@ -583,12 +592,18 @@ func (f *Fpdf) AddPageFormat(orientationStr string, size SizeType) {
 	}
 	f.color.text = tc
 	f.colorFlag = cf
+	// Set text state
+	f.textState = ts
+	f.putTextState()
 	// 	Page header
 	if f.headerFnc != nil {
 		f.inHeader = true
 		f.headerFnc()
 		f.inHeader = false
 	}
+	if len(f.columns.widths) > 0 {
+		f.startColumns(f.y)
+	}
 	// 	Restore line width
 	if f.lineWidth != lw {
 		f.lineWidth = lw
@@ -612,6 +627,12 @@ func (f *Fpdf) AddPageFormat(orientationStr string, size SizeType) {
 	}
 	f.color.text = tc
 	f.colorFlag = cf
+	// Restore text state
+	f.SetTextRenderingMode(ts.render)
+	f.SetCharSpacing(ts.charSpacing)
+	f.SetWordSpacing(ts.wordSpacing)
+	f.SetHorizontalScaling(ts.scale)
+	f.SetTextRise(ts.rise)
 	return
 }
 
@@ -723,19 +744,573 @@ func (f *Fpdf) GetTextColor() (int, int, int) {
 }
 
 // GetStringWidth returns the length of a string in user units. A font must be
-// currently selected.
+// currently selected. If the current font is a Unicode font (see
+// AddUTF8Font()), s is interpreted as UTF-8; otherwise each byte of s is a
+// character. Kerning, character and word spacing and horizontal scaling are
+// taken into account.
 func (f *Fpdf) GetStringWidth(s string) float64 {
 	if f.err != nil {
 		return 0
 	}
-	w := 0
-	for _, ch := range []byte(s) {
-		if ch == 0 {
+	if f.currentFont.utf8 == nil && f.currentFont.cid == nil {
+		if pos := strings.IndexByte(s, 0); pos >= 0 {
+			s = s[:pos]
+		}
+	}
+	wd := 0.0
+	for _, g := range f.glyphs(s) {
+		wd += f.glyphAdvance(g)
+	}
+	return wd * f.fontSize / 1000
+}
+
+// Returns the character at byte position i of s and its length in bytes. If
+// the current font is a Unicode font, s is decoded as UTF-8; otherwise each
+// byte is a character.
+func (f *Fpdf) nextChar(s string, i int) (r rune, size int) {
+	if f.currentFont.utf8 != nil || f.currentFont.cid != nil {
+		return utf8.DecodeRuneInString(s[i:])
+	}
+	return rune(s[i]), 1
+}
+
+// Returns the font definition with the specified key, or the current font if
+// key is empty
+func (f *Fpdf) glyphFont(key string) fontDefType {
+	if key == "" {
+		return f.currentFont
+	}
+	return f.fonts[key]
+}
+
+// Returns the glyph that represents r in the current Unicode font. If the
+// current font does not contain it, the fallback fonts of the current family
+// (see SetFontFallback()) are searched in order. The key of the fallback font
+// that contains the glyph, or an empty string for the current font, is
+// returned along with the font itself. A zero glyph index denotes the missing
+// glyph of the current font.
+func (f *Fpdf) fontGlyph(r rune) (gid uint16, key string, font *utf8FontType) {
+	font = f.currentFont.utf8
+	gid = font.glyphIndex(r)
+	if gid != 0 {
+		return
+	}
+	for _, family := range f.fallbacks[f.fontFamily] {
+		for _, fbKey := range []string{family + f.fontStyle, family} {
+			if def, ok := f.fonts[fbKey]; ok && def.utf8 != nil {
+				if fbGid := def.utf8.glyphIndex(r); fbGid != 0 {
+					return fbGid, fbKey, def.utf8
+				}
+				break
+			}
+		}
+	}
+	return
+}
+
+// Converts s to the glyphs that represent it in the current font, in logical
+// order. Arabic letters are shaped and, if enabled, ligatures are substituted.
+// Kerning is not applied.
+func (f *Fpdf) logicalGlyphs(s string) (list []glyphType) {
+	list = make([]glyphType, 0, len(s))
+	utf := f.currentFont.utf8
+	if cid := f.currentFont.cid; cid != nil {
+		for pos, r := range s {
+			list = append(list, glyphType{code: cid.code(r), wd: cid.width(r), text: []rune{r}, pos: pos})
+		}
+		return
+	}
+	if utf == nil {
+		for j := 0; j < len(s); j++ {
+			ch := s[j]
+			list = append(list, glyphType{code: uint16(ch), wd: f.currentFont.Cw[ch], text: []rune{rune(ch)}, pos: j})
+		}
+		f.smallCapGlyphs(list)
+		return
+	}
+	runes := make([]rune, 0, len(s))
+	positions := make([]int, 0, len(s))
+	for pos, r := range s {
+		runes = append(runes, r)
+		positions = append(positions, pos)
+	}
+	shaped := arabicShape(runes, func(r rune) bool {
+		gid, _, _ := f.fontGlyph(r)
+		return gid != 0
+	})
+	for j, r := range shaped {
+		if r < 0 {
+			// Absorbed into a ligature with the preceding character
+			last := &list[len(list)-1]
+			last.text = append(last.text, runes[j])
+			continue
+		}
+		gid, key, font := f.fontGlyph(r)
+		list = append(list, glyphType{code: gid, wd: font.glyphWidth(gid), text: []rune{runes[j]}, pos: positions[j], font: key})
+	}
+	f.smallCapGlyphs(list)
+	var tags []string
+	if f.ligatures {
+		tags = append(tags, "liga")
+	}
+	if f.dligatures {
+		tags = append(tags, "dlig")
+	}
+	if len(tags) > 0 {
+		list = utf.substitute(list, tags...)
+	}
+	return
+}
+
+// Sets the kerning adjustment between each glyph of list and the next if
+// kerning is enabled
+func (f *Fpdf) kern(list []glyphType) {
+	if !f.kerning {
+		return
+	}
+	utf := f.currentFont.utf8
+	for j := 0; j+1 < len(list); j++ {
+		if list[j].caps || list[j+1].caps {
+			continue
+		}
+		if utf != nil {
+			if list[j].font == list[j+1].font {
+				list[j].kern = f.glyphFont(list[j].font).utf8.glyphKern(list[j].code, list[j+1].code)
+			}
+		} else {
+			list[j].kern = f.currentFont.Kp[int(list[j].code)][int(list[j+1].code)]
+		}
+	}
+}
+
+// Converts s to the glyphs that represent it in the current font, in the
+// order in which they are displayed. Text in a Unicode font is reordered with
+// the bidirectional algorithm if the paragraph direction is right-to-left or
+// if s contains right-to-left characters. If kerning is enabled, the
+// adjustment between each glyph and the next is included.
+func (f *Fpdf) glyphs(s string) (list []glyphType) {
+	list = f.logicalGlyphs(s)
+	if f.currentFont.utf8 != nil {
+		runes := []rune(s)
+		if f.rtl || bidiHasRTL(runes) {
+			para := 0
+			if f.rtl {
+				para = 1
+			}
+			levels := bidiLevels(runes, para)
+			// Rune index of each byte position
+			index := make([]int, len(s))
+			k := 0
+			for pos := range s {
+				index[pos] = k
+				k++
+			}
+			visible := list[:0]
+			var glyphLevels []int
+			for _, g := range list {
+				if len(g.text) == 1 && bidiIsControl(g.text[0]) {
+					continue
+				}
+				level := levels[index[g.pos]]
+				if level&1 != 0 && len(g.text) == 1 {
+					if m, ok := bidiMirrors[g.text[0]]; ok {
+						if gid, key, font := f.fontGlyph(m); gid != 0 {
+							g = glyphType{code: gid, wd: font.glyphWidth(gid), text: []rune{m}, pos: g.pos, font: key}
+						}
+					}
+				}
+				visible = append(visible, g)
+				glyphLevels = append(glyphLevels, level)
+			}
+			order := bidiVisualOrder(glyphLevels)
+			list = make([]glyphType, len(visible))
+			for j, k := range order {
+				list[j] = visible[k]
+			}
+		}
+	}
+	f.kern(list)
+	return
+}
+
+// Returns the advance of glyph g in thousandths of the font size, including
+// kerning, character and word spacing and horizontal scaling
+func (f *Fpdf) glyphAdvance(g glyphType) float64 {
+	adv := float64(g.wd + g.kern)
+	if f.synth.bold {
+		adv += synthBoldStroke * 1000
+	}
+	if f.fontSize > 0 {
+		adv += f.textState.charSpacing * 1000 / f.fontSize
+		if len(g.text) == 1 && g.text[0] == ' ' {
+			adv += f.textState.wordSpacing * 1000 / f.fontSize
+		}
+	}
+	return adv * f.textState.scale / 100
+}
+
+// Returns the advance of each character of s in thousandths of the font size,
+// indexed by byte position. The advance of a glyph that represents several
+// characters, such as a ligature, is assigned to its first character; the
+// other positions are zero. Kerning, character and word spacing and
+// horizontal scaling are included.
+func (f *Fpdf) advances(s string) (adv []float64) {
+	list := f.logicalGlyphs(s)
+	f.kern(list)
+	adv = make([]float64, len(s))
+	for _, g := range list {
+		adv[g.pos] = f.glyphAdvance(g)
+	}
+	return
+}
+
+// Returns the byte position at which the line of s that begins at byte
+// position j can be hyphenated, given that the line overflows the width wmax
+// (in thousandths of the font size) at byte position i and that the last
+// separator of the line is at byte position sep, or -1 if there is none. The
+// width of the line up to that position, including the hyphen, is returned
+// as well. The position is -1 if no hyphenation function is set, if the
+// overflow occurs at a separator or if no hyphen fits.
+func (f *Fpdf) hyphenBreak(s string, adv []float64, j, i, sep int, wmax float64) (pos int, wd float64) {
+	pos = -1
+	if f.hyphenFnc == nil || sep == i {
+		return
+	}
+	start := j
+	if sep >= 0 {
+		start = sep + 1
+	}
+	end := start
+	for end < len(s) && s[end] != ' ' && s[end] != '\t' && s[end] != '\n' {
+		end++
+	}
+	var word []rune
+	var offsets []int
+	for k := start; k < end; {
+		r, size := f.nextChar(s, k)
+		word = append(word, r)
+		offsets = append(offsets, k)
+		k += size
+	}
+	hw := f.advances("-")[0]
+	for _, k := range f.hyphenFnc(word) {
+		if k <= 0 || k >= len(word) {
+			continue
+		}
+		w := hw
+		for _, a := range adv[j:offsets[k]] {
+			w += a
+		}
+		if w > wmax {
 			break
 		}
-		w += f.currentFont.Cw[ch]
+		pos, wd = offsets[k], w
+	}
+	return
+}
+
+// Returns the text-showing operation that displays s with the current font.
+// Glyphs of a Unicode font are recorded so that they are included in the
+// embedded font subset. Since word spacing does not apply to the two-byte
+// codes of a Unicode font, it is emulated with explicit positioning. Kerning
+// adjustments are likewise expressed as positioning in a TJ array. Runs of
+// glyphs from fallback fonts are preceded by a switch to the fallback font,
+// and the current font is selected again at the end.
+func (f *Fpdf) showText(s string) string {
+	list := f.glyphs(s)
+	var b fmtBuffer
+	key := ""
+	caps := false
+	for len(list) > 0 {
+		n := 1
+		for n < len(list) && list[n].font == list[0].font && list[n].caps == list[0].caps {
+			n++
+		}
+		if list[0].font != key || list[0].caps != caps {
+			key, caps = list[0].font, list[0].caps
+			size := f.fontSizePt
+			if caps {
+				size *= smallCapsScale
+			}
+			b.printf("/F%d %.2f Tf ", f.glyphFont(key).I, size)
+		}
+		f.showGlyphs(&b, list[:n], f.glyphFont(key))
+		list = list[n:]
+		if len(list) > 0 {
+			b.WriteString(" ")
+		}
+	}
+	if key != "" || caps {
+		b.printf(" /F%d %.2f Tf", f.currentFont.I, f.fontSizePt)
+	}
+	if b.Len() == 0 {
+		b.WriteString("() Tj")
+	}
+	return b.String()
+}
+
+// Writes the text-showing operation for the specified glyphs of font to b
+func (f *Fpdf) showGlyphs(b *fmtBuffer, list []glyphType, font fontDefType) {
+	utf := font.utf8
+	wide := utf != nil || font.cid != nil
+	spaceAdj := 0.0
+	if ws := f.wordSpacing(); wide && ws != 0 && f.fontSize > 0 {
+		spaceAdj = -ws * 1000 / f.fontSize
+	}
+	var run fmtBuffer
+	adjusted := false
+	flush := func() {
+		if wide {
+			b.printf("<%s>", run.String())
+		} else {
+			b.printf("(%s)", f.escape(run.String()))
+		}
+		run.Truncate(0)
+	}
+	for _, g := range list {
+		if utf != nil {
+			if _, ok := utf.used[g.code]; !ok && !f.silent {
+				utf.used[g.code] = g.text
+			}
+			run.printf("%04X", utf.glyphCode(g.code))
+		} else if wide {
+			run.printf("%04X", g.code)
+		} else {
+			run.WriteByte(byte(g.code))
+		}
+		adj := -float64(g.kern)
+		if spaceAdj != 0 && len(g.text) == 1 && g.text[0] == ' ' {
+			adj += spaceAdj
+		}
+		if adj != 0 {
+			if !adjusted {
+				b.WriteString("[")
+				adjusted = true
+			}
+			flush()
+			b.printf(" %.3f ", adj)
+		}
+	}
+	if adjusted {
+		flush()
+		b.WriteString("] TJ")
+	} else {
+		flush()
+		b.WriteString(" Tj")
+	}
+}
+
+// SetLigatures enables or disables the substitution of ligatures in text that
+// is written with a Unicode font (see AddUTF8Font()). If standard is true,
+// the common ligatures of the font, for example "fi", "fl" and "ffi", are
+// used. If discretionary is true, the font's discretionary ligatures, which
+// are intended for special effect, are used as well. The text that is
+// extracted from the document is not affected: ligatures are mapped back to
+// the characters they represent. Ligatures are disabled by default.
+//
+// See tutorial 32 for an example of this function.
+func (f *Fpdf) SetLigatures(standard, discretionary bool) {
+	f.ligatures = standard
+	f.dligatures = discretionary
+}
+
+// SetKerning enables or disables pair kerning. When enabled, the space
+// between pairs of characters such as "AV" or "To" is adjusted as specified
+// by the current font. This applies to text output as well as to the
+// measurement of text with GetStringWidth() and SplitLines(). Kerning pairs
+// are available for Unicode fonts (see AddUTF8Font()) and for fonts whose
+// definition file was generated from a TrueType or OpenType font with kerning
+// information. Kerning is disabled by default.
+//
+// See tutorial 31 for an example of this function.
+func (f *Fpdf) SetKerning(on bool) {
+	f.kerning = on
+}
+
+// SetRightToLeft sets the paragraph direction. If rtl is true, text is laid
+// out as right-to-left paragraphs: CellFormat() and MultiCell() align text to
+// the right unless another alignment is specified, and the last line of
+// justified text is aligned to the right as well.
+//
+// Text written with a Unicode font (see AddUTF8Font()) is displayed in the
+// order given by the Unicode bidirectional algorithm, so that Arabic and
+// Hebrew can be mixed with left-to-right text and numbers. The paragraph
+// direction is the base direction of the algorithm. Arabic letters are
+// replaced by their contextual forms. Line breaking in MultiCell(), Write()
+// and SplitLines() operates on the text in logical order; each line is then
+// reordered independently. Write() always flows from left to right. The
+// default direction is left-to-right.
+//
+// See tutorial 33 for an example of this function.
+func (f *Fpdf) SetRightToLeft(rtl bool) {
+	f.rtl = rtl
+}
+
+// GetRightToLeft returns true if the paragraph direction is right-to-left.
+// See SetRightToLeft().
+func (f *Fpdf) GetRightToLeft() bool {
+	return f.rtl
+}
+
+// SetTextRenderingMode sets the way in which glyphs are painted: filled
+// (CnTextRenderFill, the default), stroked (CnTextRenderStroke), filled and
+// stroked (CnTextRenderFillStroke) or invisible (CnTextRenderInvisible).
+// Strokes use the current draw color and line width. The modes
+// CnTextRenderFillClip, CnTextRenderStrokeClip, CnTextRenderFillStrokeClip and
+// CnTextRenderClip additionally add the glyphs to the clipping path, which
+// remains in effect until the graphics state is restored; call these within
+// a clipping operation, for example after ClipRect(), so that ClipEnd()
+// restores it. The method can be called before the first page is created. The
+// value is retained from page to page.
+//
+// See tutorial 35 for an example of this function.
+func (f *Fpdf) SetTextRenderingMode(mode int) {
+	if mode < CnTextRenderFill || mode > CnTextRenderClip {
+		f.err = fmt.Errorf("invalid text rendering mode %d", mode)
+		return
+	}
+	if f.textState.render != mode {
+		f.textState.render = mode
+		if f.page > 0 {
+			f.outf("%d Tr", mode)
+		}
+	}
+}
+
+// GetTextRenderingMode returns the current text rendering mode. See
+// SetTextRenderingMode().
+func (f *Fpdf) GetTextRenderingMode() int {
+	return f.textState.render
+}
+
+// SetCharSpacing sets the amount of space, in the unit of measure specified
+// in New(), that is added after each character. Negative values bring
+// characters closer together. The spacing is included in the widths returned
+// by GetStringWidth() and used for line breaking. The method can be called
+// before the first page is created. The value is retained from page to page.
+//
+// See tutorial 35 for an example of this function.
+func (f *Fpdf) SetCharSpacing(space float64) {
+	if f.textState.charSpacing != space {
+		f.textState.charSpacing = space
+		if f.page > 0 {
+			f.outf("%.3f Tc", space*f.k)
+		}
 	}
-	return float64(w) * f.fontSize / 1000
+}
+
+// GetCharSpacing returns the current character spacing in the unit of measure
+// specified in New().
+func (f *Fpdf) GetCharSpacing() float64 {
+	return f.textState.charSpacing
+}
+
+// SetWordSpacing sets the amount of space, in the unit of measure specified
+// in New(), that is added to each space character. The spacing is included
+// in the widths returned by GetStringWidth() and used for line breaking. When
+// MultiCell() justifies text, the space it distributes is added to this
+// value. The method can be called before the first page is created. The value
+// is retained from page to page.
+//
+// See tutorial 35 for an example of this function.
+func (f *Fpdf) SetWordSpacing(space float64) {
+	if f.textState.wordSpacing != space {
+		f.textState.wordSpacing = space
+		if f.page > 0 {
+			f.putWordSpacing()
+		}
+	}
+}
+
+// GetWordSpacing returns the current word spacing in the unit of measure
+// specified in New(). Spacing added by MultiCell() to justify text is not
+// included.
+func (f *Fpdf) GetWordSpacing() float64 {
+	return f.textState.wordSpacing
+}
+
+// SetHorizontalScaling stretches or compresses text horizontally. scale is
+// the percentage of the normal width of characters; the default is 100.
+// Scaling applies to character and word spacing as well and is included in
+// the widths returned by GetStringWidth(). The method can be called before
+// the first page is created. The value is retained from page to page.
+//
+// See tutorial 35 for an example of this function.
+func (f *Fpdf) SetHorizontalScaling(scale float64) {
+	if scale <= 0 {
+		f.err = fmt.Errorf("invalid horizontal scaling %.2f", scale)
+		return
+	}
+	if f.textState.scale != scale {
+		f.textState.scale = scale
+		if f.page > 0 {
+			f.outf("%.2f Tz", scale)
+			f.putWordSpacing()
+		}
+	}
+}
+
+// GetHorizontalScaling returns the current horizontal scaling of text in
+// percent.
+func (f *Fpdf) GetHorizontalScaling() float64 {
+	return f.textState.scale
+}
+
+// SetTextRise moves the baseline of subsequent text up by rise, in the unit
+// of measure specified in New(), or down if rise is negative. Together with a
+// smaller font size this can be used for superscripts and subscripts. The
+// current position and line height are not affected. The method can be
+// called before the first page is created. The value is retained from page to
+// page.
+//
+// See tutorial 35 for an example of this function.
+func (f *Fpdf) SetTextRise(rise float64) {
+	if f.textState.rise != rise {
+		f.textState.rise = rise
+		if f.page > 0 {
+			f.outf("%.3f Ts", rise*f.k)
+		}
+	}
+}
+
+// GetTextRise returns the current baseline rise in the unit of measure
+// specified in New().
+func (f *Fpdf) GetTextRise() float64 {
+	return f.textState.rise
+}
+
+// Outputs the text state parameters that differ from their defaults, at the
+// start of a page
+func (f *Fpdf) putTextState() {
+	ts := f.textState
+	if ts.render != CnTextRenderFill {
+		f.outf("%d Tr", ts.render)
+	}
+	if ts.charSpacing != 0 {
+		f.outf("%.3f Tc", ts.charSpacing*f.k)
+	}
+	if ts.scale != 100 {
+		f.outf("%.2f Tz", ts.scale)
+	}
+	if ts.wordSpacing != 0 {
+		f.putWordSpacing()
+	}
+	if ts.rise != 0 {
+		f.outf("%.3f Ts", ts.rise*f.k)
+	}
+}
+
+// Returns the word spacing of the text state in unscaled user units: the
+// spacing set with SetWordSpacing() plus the spacing added by MultiCell() to
+// justify text, which is expressed as displayed and is therefore divided by
+// the horizontal scaling
+func (f *Fpdf) wordSpacing() float64 {
+	return f.ws*100/f.textState.scale + f.textState.wordSpacing
+}
+
+// Outputs the current word spacing
+func (f *Fpdf) putWordSpacing() {
+	f.outf("%.3f Tw", f.wordSpacing()*f.k)
 }
 
 // SetLineWidth defines the line width. By default, the value equals 0.2 mm.
@@ -854,7 +1429,7 @@ func (f *Fpdf) Polygon(points []PointType, styleStr string) {
 			}
 		}
 		f.outf("%.5f %.5f l ", points[0].X*f.k, (f.h-points[0].Y)*f.k)
-		f.outf(fillDrawOp(styleStr))
+		f.out(fillDrawOp(styleStr))
 	}
 }
 
@@ -889,7 +1464,7 @@ func (f *Fpdf) Beziergon(points []PointType, styleStr string) {
 		points = points[3:]
 	}
 
-	f.outf(fillDrawOp(styleStr))
+	f.out(fillDrawOp(styleStr))
 }
 
 // Outputs current point
@@ -1161,7 +1736,7 @@ func (f *Fpdf) ClipRect(x, y, w, h float64, outline bool) {
 // See tutorial 14 for an example of this function.
 func (f *Fpdf) ClipText(x, y float64, txtStr string, outline bool) {
 	f.clipNest++
-	f.outf("q BT %.5f %.5f Td %d Tr (%s) Tj ET", x*f.k, (f.h-y)*f.k, intIf(outline, 5, 7), f.escape(txtStr))
+	f.outf("q BT %.5f %.5f Td %d Tr %s ET", x*f.k, (f.h-y)*f.k, intIf(outline, 5, 7), f.showText(txtStr))
 }
 
 func (f *Fpdf) clipArc(x1, y1, x2, y2, x3, y3 float64) {
@@ -1355,6 +1930,114 @@ func (f *Fpdf) AddFontFromReader(familyStr, styleStr string, r io.Reader) {
 	if f.err != nil {
 		return
 	}
+	f.addFontDef(fontkey, info)
+}
+
+// AddFontFromBytes imports a TrueType or OpenType font from the contents of
+// the font file, fontBytes, and makes it available. Unlike AddFont() and
+// AddFontFromReader(), no font definition file needs to be generated with the
+// makefont utility beforehand and no files are read: the definition and the
+// compressed font file are built in memory. This allows, for example, fonts
+// that are embedded in an application with go:embed to be used.
+//
+// mapBytes is the contents of an encoding map such as "cp1252.map" in the font
+// directory; it specifies the single-byte encoding of text written with the
+// font. If mapBytes is nil, the encoding cp1252 is used. To write UTF-8 text
+// instead, use AddUTF8FontFromBytes(). The font is embedded in the document.
+// See AddFont() for details about familyStr and styleStr.
+//
+// See tutorial 45 for an example of this function.
+func (f *Fpdf) AddFontFromBytes(familyStr, styleStr string, fontBytes, mapBytes []byte) {
+	if f.err != nil {
+		return
+	}
+	familyStr = strings.ToLower(familyStr)
+	styleStr = strings.ToUpper(styleStr)
+	if styleStr == "IB" {
+		styleStr = "BI"
+	}
+	fontkey := familyStr + styleStr
+	if _, ok := f.fonts[fontkey]; ok {
+		return
+	}
+	refList, err := loadMapFromReader(strings.NewReader(embeddedMapList["cp1252"]))
+	if err != nil {
+		f.err = err
+		return
+	}
+	encStr := "cp1252"
+	encList := refList
+	if mapBytes != nil {
+		encStr = ""
+		if encList, err = loadMapFromReader(bytes.NewReader(mapBytes)); err != nil {
+			f.err = err
+			return
+		}
+	}
+	info, err := getInfoFromTrueTypeBytes(fontBytes, ioutil.Discard, true, encList)
+	if err != nil {
+		f.err = err
+		return
+	}
+	tpStr := "TrueType"
+	if info.CFF {
+		tpStr = "OpenType"
+	}
+	// The compressed font file is identified by a name that cannot refer to
+	// a file in the font directory
+	info.File = "<" + fontkey + ">.z"
+	def := makeFontDef(tpStr, encStr, encList, refList, info)
+	f.addFontDef(fontkey, def)
+	file := f.fontFiles[def.File]
+	file.content = sliceCompress(info.Data)
+	f.fontFiles[def.File] = file
+}
+
+// AddFontFromDefinition imports a font from the contents of a font definition
+// file, definition, and of the corresponding compressed font file,
+// compressedFont, as generated by the makefont utility, and makes it
+// available. compressedFont is nil if the font is not embedded. No files are
+// read, so that fonts can be compiled into an application; MakeFontSource()
+// generates Go source that calls this method. See AddFont() for details about
+// familyStr and styleStr.
+//
+// See tutorial 46 for an example of this function.
+func (f *Fpdf) AddFontFromDefinition(familyStr, styleStr string, definition, compressedFont []byte) {
+	if f.err != nil {
+		return
+	}
+	familyStr = strings.ToLower(familyStr)
+	styleStr = strings.ToUpper(styleStr)
+	if styleStr == "IB" {
+		styleStr = "BI"
+	}
+	fontkey := familyStr + styleStr
+	if _, ok := f.fonts[fontkey]; ok {
+		return
+	}
+	info := f.loadfont(bytes.NewReader(definition))
+	if f.err != nil {
+		return
+	}
+	if info.File != "" {
+		if compressedFont == nil {
+			f.err = fmt.Errorf("font file %s is required for font %s", info.File, info.Name)
+			return
+		}
+		// The compressed font file is identified by a name that cannot refer
+		// to a file in the font directory
+		info.File = "<" + fontkey + ">" + path.Ext(info.File)
+	}
+	f.addFontDef(fontkey, info)
+	if info.File != "" {
+		file := f.fontFiles[info.File]
+		file.content = compressedFont
+		f.fontFiles[info.File] = file
+	}
+}
+
+// addFontDef makes the font defined by info available as fontkey
+func (f *Fpdf) addFontDef(fontkey string, info fontDefType) {
 	info.I = len(f.fonts)
 	if len(info.Diff) > 0 {
 		// Search existing encodings
@@ -1376,6 +2059,8 @@ func (f *Fpdf) AddFontFromReader(familyStr, styleStr string, r io.Reader) {
 		// Embedded font
 		if info.Tp == "TrueType" {
 			f.fontFiles[info.File] = fontFileType{length1: int64(info.OriginalSize)}
+		} else if info.Tp == "OpenType" {
+			f.fontFiles[info.File] = fontFileType{subtype: "OpenType"}
 		} else {
 			f.fontFiles[info.File] = fontFileType{length1: int64(info.Size1), length2: int64(info.Size2)}
 		}
@@ -1402,10 +2087,18 @@ func (f *Fpdf) AddFontFromReader(familyStr, styleStr string, r io.Reader) {
 // familyStr specifies the font family. It can be either a name defined by
 // AddFont(), AddFontFromReader() or one of the standard families (case
 // insensitive): "Courier" for fixed-width, "Helvetica" or "Arial" for sans
-// serif, "Times" for serif, "Symbol" or "ZapfDingbats" for symbolic.
+// serif, "Times" for serif, "Symbol" or "ZapfDingbats" for symbolic. Families
+// found by RegisterFontDirectory() are loaded when they are first selected;
+// their names are matched without regard to case or spaces, and each font is
+// embedded once however its name is written.
 //
-// styleStr can be "B" (bold), "I" (italic), "U" (underscore) or any
-// combination. The default value (specified with an empty string) is regular.
+// styleStr can be "B" (bold), "I" (italic), "U" (underscore), "S"
+// (strikethrough), "O" (overline), "C" (small capitals) or any combination.
+// The default value (specified with an empty string) is regular. The
+// appearance of the lines drawn for "U", "S" and "O" can be changed with
+// SetTextDecorationStyle() and SetTextDecorationColor(). Bold and italic
+// styles that have not been added for the family can be emulated with
+// SetSyntheticStyles(); otherwise an error is set.
 // Bold and italic styles do not apply to Symbol and ZapfDingbats.
 //
 // size is the font size measured in points. The default value is the current
@@ -1426,8 +2119,11 @@ func (f *Fpdf) SetFont(familyStr, styleStr string, size float64) {
 	}
 	styleStr = strings.ToUpper(styleStr)
 	f.underline = strings.Contains(styleStr, "U")
-	if f.underline {
-		styleStr = strings.Replace(styleStr, "U", "", -1)
+	f.strikeout = strings.Contains(styleStr, "S")
+	f.overline = strings.Contains(styleStr, "O")
+	f.synth.smallCaps = strings.Contains(styleStr, "C")
+	for _, c := range []string{"U", "S", "O", "C"} {
+		styleStr = strings.Replace(styleStr, c, "", -1)
 	}
 	if styleStr == "IB" {
 		styleStr = "BI"
@@ -1435,12 +2131,18 @@ func (f *Fpdf) SetFont(familyStr, styleStr string, size float64) {
 	if size == 0.0 {
 		size = f.fontSizePt
 	}
+	// Fonts of the registry are loaded once under the name of their family
+	// without spaces, however the name is written
+	if _, ok = f.fonts[familyStr+styleStr]; !ok && len(f.RegisteredFontStyles(familyStr)) > 0 {
+		familyStr = registryFamily(familyStr)
+	}
 	// Test if font is already selected
 	if f.fontFamily == familyStr && f.fontStyle == styleStr && f.fontSizePt == size {
 		return
 	}
 	// Test if font is already loaded
 	fontkey := familyStr + styleStr
+	baseStr := styleStr
 	_, ok = f.fonts[fontkey]
 	if !ok {
 		// Test if one of the core fonts
@@ -1466,8 +2168,17 @@ func (f *Fpdf) SetFont(familyStr, styleStr string, size float64) {
 					return
 				}
 			}
+		} else if reg, ok := f.fontRegistry[registryFamily(familyStr)+styleStr]; ok {
+			f.addRegisteredFont(familyStr, styleStr, reg)
+			if f.err != nil {
+				return
+			}
+		} else if baseStr, ok = f.synthBase(familyStr, styleStr); ok {
+			fontkey = familyStr + baseStr
 		} else {
-			f.err = fmt.Errorf("undefined font: %s %s", familyStr, styleStr)
+			if f.err == nil {
+				f.err = fmt.Errorf("undefined font: %s %s", familyStr, styleStr)
+			}
 			return
 		}
 	}
@@ -1477,12 +2188,45 @@ func (f *Fpdf) SetFont(familyStr, styleStr string, size float64) {
 	f.fontSizePt = size
 	f.fontSize = size / f.k
 	f.currentFont = f.fonts[fontkey]
+	f.synth.bold = strings.Contains(styleStr, "B") && !strings.Contains(baseStr, "B")
+	f.synth.italic = strings.Contains(styleStr, "I") && !strings.Contains(baseStr, "I")
 	if f.page > 0 {
 		f.outf("BT /F%d %.2f Tf ET", f.currentFont.I, f.fontSizePt)
 	}
 	return
 }
 
+// SetFontFallback specifies the font families that supply the characters
+// missing from the Unicode font family primaryFamily (see AddUTF8Font()).
+// When text is written or measured with primaryFamily as the current font,
+// each character that the font does not contain is taken from the first
+// fallback family that does. The fallback font of the current style is used
+// if it has been added, otherwise the regular style. Font switches are
+// written to the content stream around runs of characters from fallback
+// fonts. This applies to Cell(), CellFormat(), MultiCell(), Write(),
+// GetStringWidth() and SplitLines().
+//
+// Fallback families must be Unicode fonts; other families are ignored. The
+// fonts can be added before or after this call. Calling SetFontFallback()
+// without fallback families removes the fallback chain of primaryFamily.
+//
+// See tutorial 34 for an example of this function.
+func (f *Fpdf) SetFontFallback(primaryFamily string, fallbackFamilies ...string) {
+	primaryFamily = strings.ToLower(primaryFamily)
+	if len(fallbackFamilies) == 0 {
+		delete(f.fallbacks, primaryFamily)
+		return
+	}
+	if f.fallbacks == nil {
+		f.fallbacks = make(map[string][]string)
+	}
+	list := make([]string, len(fallbackFamilies))
+	for j, family := range fallbackFamilies {
+		list[j] = strings.ToLower(family)
+	}
+	f.fallbacks[primaryFamily] = list
+}
+
 // SetFontSize defines the size of the current font in points.
 func (f *Fpdf) SetFontSize(size float64) {
 	if f.fontSizePt == size {
@@ -1524,6 +2268,9 @@ func (f *Fpdf) SetLink(link int, y float64, page int) {
 
 // Add a new clickable link on current page
 func (f *Fpdf) newLink(x, y, w, h float64, link int, linkStr string) {
+	if f.silent {
+		return
+	}
 	// linkList, ok := f.pageLinks[f.page]
 	// if !ok {
 	// linkList = make([]linkType, 0, 8)
@@ -1567,10 +2314,14 @@ func (f *Fpdf) Bookmark(txtStr string, level int, y float64) {
 // first character at the baseline. This method permits a string to be placed
 // precisely on the page, but it is usually easier to use Cell(), MultiCell()
 // or Write() which are the standard methods to print text.
+//
+// If the current font is a vertical CJK font (see AddCIDFont()), the
+// characters are stacked downward; x is the center of the column and y is the
+// top of the first character.
 func (f *Fpdf) Text(x, y float64, txtStr string) {
-	s := sprintf("BT %.2f %.2f Td (%s) Tj ET", x*f.k, (f.h-y)*f.k, f.escape(txtStr))
-	if f.underline && txtStr != "" {
-		s += " " + f.dounderline(x, y, txtStr)
+	s := f.textObject(x*f.k, (f.h-y)*f.k, txtStr)
+	if f.decorated() && txtStr != "" && !f.vertical() {
+		s += " " + f.dodecoration(x, y, txtStr)
 	}
 	if f.colorFlag {
 		s = sprintf("q %s %s Q", f.color.text.str, s)
@@ -1606,7 +2357,8 @@ func (f *Fpdf) SetAcceptPageBreakFunc(fnc func() bool) {
 // extends up to the right margin. Specifying 0 for h will result in no output,
 // but the current position will be advanced by w.
 //
-// txtStr specifies the text to display.
+// txtStr specifies the text to display. Text that is wider than the cell is
+// treated as specified with SetCellOverflow().
 //
 // borderStr specifies how the cell border will be drawn. An empty string
 // indicates no border, "1" indicates a full border, and one or more of "L",
@@ -1621,7 +2373,8 @@ func (f *Fpdf) SetAcceptPageBreakFunc(fnc func() bool) {
 // Horizontal alignment is controlled by including "L", "C" or "R" (left,
 // center, right) in alignStr. Vertical alignment is controlled by including
 // "T", "M" or "B" (top, middle, bottom) in alignStr. The default alignment is
-// left middle.
+// left middle, or right middle if the paragraph direction is right-to-left
+// (see SetRightToLeft()).
 //
 // fill is true to paint the cell background or false to leave it transparent.
 //
@@ -1638,28 +2391,31 @@ func (f *Fpdf) CellFormat(w, h float64, txtStr string, borderStr string, ln int,
 	}
 	borderStr = strings.ToUpper(borderStr)
 	k := f.k
-	if f.y+h > f.pageBreakTrigger && !f.inHeader && !f.inFooter && f.acceptPageBreak() {
+	if f.y+h > f.pageBreakTrigger && !f.inHeader && !f.inFooter && f.acceptPageBreak() && !f.nextColumn() {
 		// Automatic page break
-		x := f.x
+		x := f.x - f.columnOrigin()
 		ws := f.ws
 		// dbg("auto page break, x %.2f, ws %.2f", x, ws)
 		if ws > 0 {
 			f.ws = 0
-			f.out("0 Tw")
+			f.putWordSpacing()
 		}
 		f.AddPageFormat(f.curOrientation, f.curPageSize)
 		if f.err != nil {
 			return
 		}
-		f.x = x
+		f.x = x + f.columnOrigin()
 		if ws > 0 {
 			f.ws = ws
-			f.outf("%.3f Tw", ws*k)
+			f.putWordSpacing()
 		}
 	}
 	if w == 0 {
 		w = f.w - f.rMargin - f.x
 	}
+	if f.measure != nil {
+		f.measure.cell(f.y, h)
+	}
 	var s fmtBuffer
 	if fill || borderStr == "1" {
 		var op string
@@ -1699,10 +2455,42 @@ func (f *Fpdf) CellFormat(w, h float64, txtStr string, borderStr string, ln int,
 			s.printf("%.2f %.2f m %.2f %.2f l S ", left, bottom, right, bottom)
 		}
 	}
+	var restoreSize, restoreScale float64
+	clip := false
+	if len(txtStr) > 0 && f.cellOverflow != "visible" {
+		if avail := w - 2*f.cMargin; f.GetStringWidth(txtStr) > avail {
+			switch f.cellOverflow {
+			case "clip":
+				// The border and fill precede the clipping path
+				clip = true
+				if s.Len() > 0 {
+					f.out(s.String())
+					s.Reset()
+				}
+				f.ClipRect(f.x, f.y, w, h, false)
+			case "ellipsis":
+				txtStr = f.truncate(txtStr, avail)
+			case "scale":
+				restoreScale = f.textState.scale
+				f.textState.scale *= avail / f.GetStringWidth(txtStr)
+				s.printf("%.2f Tz ", f.textState.scale)
+			case "shrink":
+				restoreSize = f.fontSizePt
+				size := f.fontSizePt * avail / f.GetStringWidth(txtStr)
+				for j := 0; j < 10; j++ {
+					f.SetFontSize(math.Floor(size*100) / 100)
+					if f.GetStringWidth(txtStr) <= avail {
+						break
+					}
+					size *= 0.99
+				}
+			}
+		}
+	}
 	if len(txtStr) > 0 {
 		var dx, dy float64
 		// Horizontal alignment
-		if strings.Index(alignStr, "R") != -1 {
+		if strings.Index(alignStr, "R") != -1 || f.rtl && f.ws == 0 && !strings.ContainsAny(alignStr, "LC") {
 			dx = w - f.cMargin - f.GetStringWidth(txtStr)
 		} else if strings.Index(alignStr, "C") != -1 {
 			dx = (w - f.GetStringWidth(txtStr)) / 2
@@ -1720,16 +2508,13 @@ func (f *Fpdf) CellFormat(w, h float64, txtStr string, borderStr string, ln int,
 		if f.colorFlag {
 			s.printf("q %s ", f.color.text.str)
 		}
-		txt2 := strings.Replace(txtStr, "\\", "\\\\", -1)
-		txt2 = strings.Replace(txt2, "(", "\\(", -1)
-		txt2 = strings.Replace(txt2, ")", "\\)", -1)
-		// if strings.Contains(txt2, "end of excerpt") {
+		// if strings.Contains(txtStr, "end of excerpt") {
 		// dbg("f.h %.2f, f.y %.2f, h %.2f, f.fontSize %.2f, k %.2f", f.h, f.y, h, f.fontSize, k)
 		// }
-		s.printf("BT %.2f %.2f Td (%s) Tj ET", (f.x+dx)*k, (f.h-(f.y+dy+.5*h+.3*f.fontSize))*k, txt2)
+		s.WriteString(f.textObject((f.x+dx)*k, (f.h-(f.y+dy+.5*h+.3*f.fontSize))*k, txtStr))
 		//BT %.2F %.2F Td (%s) Tj ET',($this->x+$dx)*$k,($this->h-($this->y+.5*$h+.3*$this->FontSize))*$k,$txt2);
-		if f.underline {
-			s.printf(" %s", f.dounderline(f.x+dx, f.y+dy+.5*h+.3*f.fontSize, txtStr))
+		if f.decorated() {
+			s.printf(" %s", f.dodecoration(f.x+dx, f.y+dy+.5*h+.3*f.fontSize, txtStr))
 		}
 		if f.colorFlag {
 			s.printf(" Q")
@@ -1737,11 +2522,21 @@ func (f *Fpdf) CellFormat(w, h float64, txtStr string, borderStr string, ln int,
 		if link > 0 || len(linkStr) > 0 {
 			f.newLink(f.x+dx, f.y+dy+.5*h-.5*f.fontSize, f.GetStringWidth(txtStr), f.fontSize, link, linkStr)
 		}
+		if restoreScale != 0 {
+			f.textState.scale = restoreScale
+			s.printf(" %.2f Tz", f.textState.scale)
+		}
 	}
 	str := s.String()
 	if len(str) > 0 {
 		f.out(str)
 	}
+	if clip {
+		f.ClipEnd()
+	}
+	if restoreSize != 0 {
+		f.SetFontSize(restoreSize)
+	}
 	f.lasth = h
 	if ln > 0 {
 		// Go to next line
@@ -1755,6 +2550,79 @@ func (f *Fpdf) CellFormat(w, h float64, txtStr string, borderStr string, ln int,
 	return
 }
 
+// SetCellOverflow specifies how CellFormat() and the functions that use it
+// treat text that is wider than the cell, less its margins. This keeps, for
+// example, the columns of a table from running into each other. modeStr is
+// one of the following:
+//
+// "visible", the default, prints the text in full beyond the cell border.
+//
+// "clip" confines the text to the cell rectangle; the part outside is not
+// shown.
+//
+// "ellipsis" truncates the text and appends an ellipsis so that it fits.
+//
+// "scale" compresses the text horizontally (see SetHorizontalScaling()) so
+// that it fits.
+//
+// "shrink" reduces the font size so that the text fits.
+//
+// Text that fits is not affected. The method can be called before the first
+// page is created and the value is retained from page to page.
+//
+// See tutorial 40 for an example of this function.
+func (f *Fpdf) SetCellOverflow(modeStr string) {
+	switch modeStr {
+	case "clip", "ellipsis", "scale", "shrink":
+		f.cellOverflow = modeStr
+	default:
+		f.cellOverflow = "visible"
+	}
+}
+
+// GetCellOverflow returns the treatment of text that is wider than a cell, as
+// set with SetCellOverflow().
+func (f *Fpdf) GetCellOverflow() string {
+	return f.cellOverflow
+}
+
+// Returns the longest leading part of s that fits within the width wd (in
+// user units) when it is followed by an ellipsis, with the ellipsis appended.
+// The ellipsis character is used if the current font has one; otherwise three
+// periods are used.
+func (f *Fpdf) truncate(s string, wd float64) string {
+	ellipsis := "..."
+	if f.currentFont.cid != nil {
+		ellipsis = "\u2026"
+	} else if f.currentFont.utf8 != nil {
+		if gid, _, _ := f.fontGlyph('\u2026'); gid != 0 {
+			ellipsis = "\u2026"
+		}
+	} else if f.currentFont.DiffN == 0 && f.currentFont.Name != "Symbol" && f.currentFont.Name != "ZapfDingbats" {
+		ellipsis = "\x85" // WinAnsiEncoding
+	}
+	wmax := (wd - f.GetStringWidth(ellipsis)) * 1000 / f.fontSize
+	adv := f.advances(s)
+	end := 0
+	l := 0.0
+	for i := 0; i < len(s); {
+		_, size := f.nextChar(s, i)
+		for _, a := range adv[i : i+size] {
+			l += a
+		}
+		if l > wmax {
+			break
+		}
+		i += size
+		end = i
+	}
+	s = strings.TrimRight(s[:end], " ")
+	if f.GetStringWidth(ellipsis) > wd {
+		return s
+	}
+	return s + ellipsis
+}
+
 // Cell is a simpler version of CellFormat with no fill, border, links or
 // special alignment.
 func (f *Fpdf) Cell(w, h float64, txtStr string) {
@@ -1768,51 +2636,161 @@ func (f *Fpdf) Cellf(w, h float64, fmtStr string, args ...interface{}) {
 	f.CellFormat(w, h, sprintf(fmtStr, args...), "", 0, "L", false, 0, "")
 }
 
+// SetHyphenFunc sets the function that is used to hyphenate words when text
+// is broken into lines by MultiCell(), Write() and SplitLines(). When a word
+// does not fit on the remainder of a line, fnc is called with the word and
+// returns the rune indexes, in increasing order, before which a hyphen may be
+// inserted. The word is broken at the last of these positions at which the
+// first part, followed by a hyphen, still fits. If the current font is not a
+// Unicode font, each byte of the word is passed as a rune.
+//
+// Hyphenator() and HyphenatorFromFile() return functions that hyphenate words
+// with TeX hyphenation patterns. Specify nil to disable hyphenation, which is
+// the default.
+//
+// See tutorial 36 for an example of this function.
+func (f *Fpdf) SetHyphenFunc(fnc func(word []rune) []int) {
+	f.hyphenFnc = fnc
+}
+
+// SetLineBreakMode selects the algorithm with which SplitLines() and
+// MultiCell() break text into lines. modeStr should be "first-fit" or
+// "total-fit".
+//
+// With "first-fit", the default, each line is filled with as many words as
+// fit before the next line is begun. With "total-fit", the line breaks of a
+// paragraph are chosen together, using the algorithm of Donald Knuth and
+// Michael Plass, so that the word spacing varies as little as possible from
+// line to line. Lines may be hyphenated at the positions reported by the
+// function set with SetHyphenFunc(), though a hyphen is only inserted if it
+// improves the paragraph as a whole. If a paragraph cannot be broken
+// acceptably, for example because a word is wider than the cell, it is broken
+// with the first-fit algorithm.
+//
+// The line structure that results is the same in both modes, so alignment,
+// borders and fill work as before. Write() always uses the first-fit
+// algorithm since its lines may begin and end partway across the page. For
+// the same reason, MultiCell() uses it while text is set in columns (see
+// SetColumns()) or exclusion regions are set on the current page (see
+// AddExclusionPolygon()).
+//
+// See tutorial 37 for an example of this function.
+func (f *Fpdf) SetLineBreakMode(modeStr string) {
+	switch modeStr {
+	case "total-fit":
+		f.lineBreakMode = modeStr
+	default:
+		f.lineBreakMode = "first-fit"
+	}
+}
+
+// GetLineBreakMode returns the line breaking algorithm set with
+// SetLineBreakMode(), either "first-fit" or "total-fit".
+func (f *Fpdf) GetLineBreakMode() string {
+	return f.lineBreakMode
+}
+
+// MultiCellMode prints text like MultiCell() but with the line breaking
+// algorithm specified by modeStr rather than the one set with
+// SetLineBreakMode(). modeStr is "first-fit" or "total-fit".
+//
+// See tutorial 37 for an example of this function.
+func (f *Fpdf) MultiCellMode(w, h float64, txtStr, borderStr, alignStr string, fill bool, modeStr string) {
+	saveStr := f.lineBreakMode
+	f.SetLineBreakMode(modeStr)
+	f.MultiCell(w, h, txtStr, borderStr, alignStr, fill)
+	f.lineBreakMode = saveStr
+}
+
 // SplitLines splits text into several lines using the current font. Each line
 // has its length limited to a maximum width given by w. This function can be
 // used to determine the total height of wrapped text for vertical placement
 // purposes.
 //
 // You can use MultiCell if you want to print a text on several lines in a
-// simple way.
+// simple way. The lines are broken with the algorithm set with
+// SetLineBreakMode().
+//
+// Each line fits within w less the cell margins, as in MultiCell(). Previous
+// versions rounded this width up to a whole thousandth of the font size, so
+// that a line could be slightly too wide and text could be broken at other
+// positions than by MultiCell().
 //
 // See tutorial 19 for an example of this function.
 func (f *Fpdf) SplitLines(txt []byte, w float64) [][]byte {
-	// Function contributed by Bruno Michel
-	lines := [][]byte{}
-	cw := &f.currentFont.Cw
-	wmax := int(math.Ceil((w - 2*f.cMargin) * 1000 / f.fontSize))
+	return f.SplitLinesMode(txt, w, f.lineBreakMode)
+}
+
+// SplitLinesMode splits text into several lines like SplitLines() but with
+// the line breaking algorithm specified by modeStr rather than the one set
+// with SetLineBreakMode(). modeStr is "first-fit" or "total-fit".
+//
+// See tutorial 37 for an example of this function.
+func (f *Fpdf) SplitLinesMode(txt []byte, w float64, modeStr string) [][]byte {
+	wmax := (w - 2*f.cMargin) * 1000 / f.fontSize
 	s := bytes.Replace(txt, []byte("\r"), []byte{}, -1)
 	nb := len(s)
 	for nb > 0 && s[nb-1] == '\n' {
 		nb--
 	}
 	s = s[0:nb]
+	if modeStr != "total-fit" {
+		return f.splitGreedy(s, wmax)
+	}
+	lines := [][]byte{}
+	if nb > 0 {
+		for _, par := range bytes.Split(s, []byte("\n")) {
+			if f.tabbed(string(par)) {
+				lines = append(lines, f.splitGreedy(par, wmax)...)
+			} else {
+				lines = append(lines, f.splitOptimal(par, wmax)...)
+			}
+		}
+	}
+	return lines
+}
+
+// Splits s, which does not end with a line feed, into lines no wider than
+// wmax (in thousandths of the font size). Each line is filled with as many
+// words as fit.
+func (f *Fpdf) splitGreedy(s []byte, wmax float64) [][]byte {
+	// Function contributed by Bruno Michel
+	lines := [][]byte{}
+	nb := len(s)
+	str := string(s)
 	sep := -1
 	i := 0
 	j := 0
-	l := 0
+	l := 0.0
+	adv := f.advances(str)
 	for i < nb {
-		c := s[i]
-		l += cw[c]
-		if c == ' ' || c == '\t' || c == '\n' {
+		c, size := f.nextChar(str, i)
+		if c == '\t' && len(f.tabStops) > 0 {
+			adv[i], _ = f.tabAdvance(str, adv, i, l+f.cMargin*1000/f.fontSize)
+		} else if c == ' ' || c == '\t' || c == '\n' {
 			sep = i
 		}
+		l += adv[i]
 		if c == '\n' || l > wmax {
-			if sep == -1 {
-				if i == j {
-					i++
-				}
-				sep = i
+			if pos, _ := f.hyphenBreak(str, adv, j, i, sep, wmax); c != '\n' && pos >= 0 {
+				lines = append(lines, append(s[j:pos:pos], '-'))
+				i = pos
 			} else {
-				i = sep + 1
+				if sep == -1 {
+					if i == j {
+						i += size
+					}
+					sep = i
+				} else {
+					i = sep + 1
+				}
+				lines = append(lines, s[j:sep])
 			}
-			lines = append(lines, s[j:sep])
 			sep = -1
 			j = i
 			l = 0
 		} else {
-			i++
+			i += size
 		}
 	}
 	if i != j {
@@ -1837,7 +2815,7 @@ func (f *Fpdf) MultiCell(w, h float64, txtStr, borderStr, alignStr string, fill
 	if alignStr == "" {
 		alignStr = "J"
 	}
-	cw := &f.currentFont.Cw
+	lineW, off := w, f.x-f.lMargin
 	if w == 0 {
 		w = f.w - f.rMargin - f.x
 	}
@@ -1872,6 +2850,10 @@ func (f *Fpdf) MultiCell(w, h float64, txtStr, borderStr, alignStr string, fill
 			}
 		}
 	}
+	if f.lineBreakMode == "total-fit" && !f.flowing() {
+		f.multiCellOptimal(w, h, s, b, b2, borderStr, alignStr, fill, wmax)
+		return
+	}
 	sep := -1
 	i := 0
 	j := 0
@@ -1879,16 +2861,23 @@ func (f *Fpdf) MultiCell(w, h float64, txtStr, borderStr, alignStr string, fill
 	ls := 0.0
 	ns := 0
 	nl := 1
+	adv := f.advances(s)
 	for i < nb {
+		if i == j && f.flowing() {
+			// The line may begin in another column or beside an exclusion
+			// region
+			w = f.lineStart(off, lineW, h)
+			wmax = (w - 2*f.cMargin) * 1000 / f.fontSize
+		}
 		// Get next character
-		c := []byte(s)[i]
+		c, size := f.nextChar(s, i)
 		if c == '\n' {
 			// Explicit line break
 			if f.ws > 0 {
 				f.ws = 0
-				f.out("0 Tw")
+				f.putWordSpacing()
 			}
-			f.CellFormat(w, h, s[j:i], b, 2, alignStr, fill, 0, "")
+			f.tabCellFormat(w, h, s[j:i], b, 2, alignStr, fill, 0, "", f.x)
 			i++
 			sep = -1
 			j = i
@@ -1905,18 +2894,34 @@ func (f *Fpdf) MultiCell(w, h float64, txtStr, borderStr, alignStr string, fill
 			ls = l
 			ns++
 		}
-		l += float64(cw[c])
+		if c == '\t' && len(f.tabStops) > 0 {
+			adv[i], _ = f.tabAdvance(s, adv, i, l+f.cMargin*1000/f.fontSize)
+		}
+		l += adv[i]
 		if l > wmax {
 			// Automatic line break
-			if sep == -1 {
+			if pos, wd := f.hyphenBreak(s, adv, j, i, sep, wmax); pos >= 0 {
+				// Hyphenated word; the separators up to this point are
+				// all within the line
+				if alignStr == "J" {
+					if ns > 0 {
+						f.ws = (wmax - wd) / 1000 * f.fontSize / float64(ns)
+					} else {
+						f.ws = 0
+					}
+					f.putWordSpacing()
+				}
+				f.tabCellFormat(w, h, s[j:pos]+"-", b, 2, alignStr, fill, 0, "", f.x)
+				i = pos
+			} else if sep == -1 {
 				if i == j {
-					i++
+					i += size
 				}
 				if f.ws > 0 {
 					f.ws = 0
-					f.out("0 Tw")
+					f.putWordSpacing()
 				}
-				f.CellFormat(w, h, s[j:i], b, 2, alignStr, fill, 0, "")
+				f.tabCellFormat(w, h, s[j:i], b, 2, alignStr, fill, 0, "", f.x)
 			} else {
 				if alignStr == "J" {
 					if ns > 1 {
@@ -1924,9 +2929,9 @@ func (f *Fpdf) MultiCell(w, h float64, txtStr, borderStr, alignStr string, fill
 					} else {
 						f.ws = 0
 					}
-					f.outf("%.3f Tw", f.ws*f.k)
+					f.putWordSpacing()
 				}
-				f.CellFormat(w, h, s[j:sep], b, 2, alignStr, fill, 0, "")
+				f.tabCellFormat(w, h, s[j:sep], b, 2, alignStr, fill, 0, "", f.x)
 				i = sep + 1
 			}
 			sep = -1
@@ -1938,25 +2943,167 @@ func (f *Fpdf) MultiCell(w, h float64, txtStr, borderStr, alignStr string, fill
 				b = b2
 			}
 		} else {
-			i++
+			i += size
 		}
 	}
 	// Last chunk
 	if f.ws > 0 {
 		f.ws = 0
-		f.out("0 Tw")
+		f.putWordSpacing()
 	}
 	if len(borderStr) > 0 && strings.Contains(borderStr, "B") {
 		b += "B"
 	}
-	f.CellFormat(w, h, s[j:i], b, 2, alignStr, fill, 0, "")
+	f.tabCellFormat(w, h, s[j:i], b, 2, alignStr, fill, 0, "", f.x)
+	f.x = f.lMargin
+}
+
+// MultiCellFit prints text in a box of fixed size with the largest font size
+// at which the text fits. The upper-left corner of the box is the current
+// position; w and h are its width and height. A width of zero indicates a box
+// that reaches to the right margin.
+//
+// The text is wrapped as with MultiCell(). The font size, in points, is chosen
+// between minSize and maxSize so that the lines, each of which is 1.2 times as
+// high as the font size, fit within the height of the box and no word is
+// broken. The size is determined to a tenth of a point.
+//
+// alignStr combines a horizontal alignment, "L", "C", "R" or "J" (the
+// default), with a vertical alignment of the text within the box: "T" for
+// top, "M" for middle (the default) or "B" for bottom. borderStr and fill
+// apply to the box as in CellFormat().
+//
+// The font size that was used is returned. overflow is true if the text does
+// not fit even at minSize; in this case it is printed at minSize from the top
+// of the box and extends below it. After the call, the font size is restored
+// and the current position is at the left margin below the box, as with
+// MultiCell().
+//
+// See tutorial 39 for an example of this function.
+func (f *Fpdf) MultiCellFit(w, h float64, txtStr, borderStr, alignStr string, fill bool, minSize, maxSize float64) (sizePt float64, overflow bool) {
+	const spacing = 1.2
+	if f.err != nil {
+		return
+	}
+	if w == 0 {
+		w = f.w - f.rMargin - f.x
+	}
+	saveSize := f.fontSizePt
+	// lineCount returns the number of lines of the text at size and whether
+	// they fit in the box without breaking a word
+	lineCount := func(size float64) (n int, ok bool) {
+		f.silently(func() {
+			f.SetFontSize(size)
+			defer f.SetFontSize(saveSize)
+			for _, word := range strings.Fields(txtStr) {
+				if f.GetStringWidth(word) > w-2*f.cMargin {
+					return
+				}
+			}
+			n = len(f.SplitLines([]byte(txtStr), w))
+			ok = float64(n)*f.fontSize*spacing <= h
+		})
+		return
+	}
+	if _, ok := lineCount(maxSize); ok {
+		sizePt = maxSize
+	} else if _, ok = lineCount(minSize); !ok {
+		sizePt = minSize
+		overflow = true
+	} else {
+		lo, hi := minSize, maxSize
+		for hi-lo > 0.1 {
+			mid := (lo + hi) / 2
+			if _, ok = lineCount(mid); ok {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		sizePt = math.Floor(lo*10) / 10
+		if sizePt < minSize {
+			sizePt = minSize
+		}
+	}
+	n, _ := lineCount(sizePt)
+	// Box, which may cause a page break
+	f.CellFormat(w, h, "", borderStr, 0, "", fill, 0, "")
+	x, y := f.x-w, f.y
+	f.SetFontSize(sizePt)
+	lineHt := f.fontSize * spacing
+	dy := 0.0
+	if !overflow {
+		switch {
+		case strings.Contains(alignStr, "T"):
+		case strings.Contains(alignStr, "B"):
+			dy = h - float64(n)*lineHt
+		default:
+			dy = (h - float64(n)*lineHt) / 2
+		}
+	}
+	hAlignStr := "J"
+	for _, str := range []string{"L", "C", "R"} {
+		if strings.Contains(alignStr, str) {
+			hAlignStr = str
+		}
+	}
+	// The text does not cause a page break even if it overflows the box
+	accept := f.acceptPageBreak
+	f.acceptPageBreak = func() bool {
+		return false
+	}
+	f.SetXY(x, y+dy)
+	f.MultiCell(w, lineHt, txtStr, "", hAlignStr, false)
+	f.acceptPageBreak = accept
+	f.SetFontSize(saveSize)
+	f.SetXY(f.lMargin, y+h)
+	return
+}
+
+// Outputs the lines of s, which are broken with the total-fit algorithm, as
+// cells. b is the border of the first line and b2 the border of the following
+// lines.
+func (f *Fpdf) multiCellOptimal(w, h float64, s, b, b2, borderStr, alignStr string, fill bool, wmax float64) {
+	pars := strings.Split(s, "\n")
+	for k, par := range pars {
+		var lines [][]byte
+		if f.tabbed(par) {
+			lines = f.splitGreedy([]byte(par), wmax)
+		} else {
+			lines = f.splitOptimal([]byte(par), wmax)
+		}
+		for m, line := range lines {
+			lineStr := string(line)
+			last := m == len(lines)-1
+			ws := 0.0
+			if alignStr == "J" && !last {
+				if ns := strings.Count(lineStr, " "); ns > 0 {
+					wd := 0.0
+					for _, a := range f.advances(lineStr) {
+						wd += a
+					}
+					ws = (wmax - wd) / 1000 * f.fontSize / float64(ns)
+				}
+			}
+			if ws != f.ws {
+				f.ws = ws
+				f.putWordSpacing()
+			}
+			if last && k == len(pars)-1 && len(borderStr) > 0 && strings.Contains(borderStr, "B") {
+				b += "B"
+			}
+			f.tabCellFormat(w, h, lineStr, b, 2, alignStr, fill, 0, "", f.x)
+			if len(borderStr) > 0 {
+				b = b2
+			}
+		}
+	}
 	f.x = f.lMargin
 }
 
 // Output text in flowing mode
 func (f *Fpdf) write(h float64, txtStr string, link int, linkStr string) {
 	// dbg("Write")
-	cw := &f.currentFont.Cw
 	w := f.w - f.rMargin - f.x
 	wmax := (w - 2*f.cMargin) * 1000 / f.fontSize
 	s := strings.Replace(txtStr, "\r", "", -1)
@@ -1966,12 +3113,23 @@ func (f *Fpdf) write(h float64, txtStr string, link int, linkStr string) {
 	j := 0
 	l := 0.0
 	nl := 1
+	adv := f.advances(s)
 	for i < nb {
+		if i == j && f.flowing() {
+			// The line may begin in another column or beside an exclusion
+			// region
+			off := 0.0
+			if nl == 1 {
+				off = f.x - f.lMargin
+			}
+			w = f.lineStart(off, 0, h)
+			wmax = (w - 2*f.cMargin) * 1000 / f.fontSize
+		}
 		// 		Get next character
-		c := []byte(s)[i]
+		c, size := f.nextChar(s, i)
 		if c == '\n' {
 			// Explicit line break
-			f.CellFormat(w, h, s[j:i], "", 2, "", false, link, linkStr)
+			f.tabCellFormat(w, h, s[j:i], "", 2, "L", false, link, linkStr, f.lMargin)
 			i++
 			sep = -1
 			j = i
@@ -1987,26 +3145,35 @@ func (f *Fpdf) write(h float64, txtStr string, link int, linkStr string) {
 		if c == ' ' {
 			sep = i
 		}
-		l += float64(cw[c])
+		if c == '\t' && len(f.tabStops) > 0 {
+			adv[i], _ = f.tabAdvance(s, adv, i, l+(f.x+f.cMargin-f.lMargin)*1000/f.fontSize)
+		}
+		l += adv[i]
 		if l > wmax {
 			// Automatic line break
-			if sep == -1 {
-				if f.x > f.lMargin {
+			if pos, _ := f.hyphenBreak(s, adv, j, i, sep, wmax); pos >= 0 {
+				f.tabCellFormat(w, h, s[j:pos]+"-", "", 2, "L", false, link, linkStr, f.lMargin)
+				i = pos
+			} else if sep == -1 {
+				if nl == 1 && f.x > f.lMargin {
 					// Move to next line
 					f.x = f.lMargin
 					f.y += h
 					w = f.w - f.rMargin - f.x
+					if f.flowing() {
+						w = f.lineStart(0, 0, h)
+					}
 					wmax = (w - 2*f.cMargin) * 1000 / f.fontSize
-					i++
+					i += size
 					nl++
 					continue
 				}
 				if i == j {
-					i++
+					i += size
 				}
-				f.CellFormat(w, h, s[j:i], "", 2, "", false, link, linkStr)
+				f.tabCellFormat(w, h, s[j:i], "", 2, "L", false, link, linkStr, f.lMargin)
 			} else {
-				f.CellFormat(w, h, s[j:sep], "", 2, "", false, link, linkStr)
+				f.tabCellFormat(w, h, s[j:sep], "", 2, "L", false, link, linkStr, f.lMargin)
 				i = sep + 1
 			}
 			sep = -1
@@ -2019,12 +3186,12 @@ func (f *Fpdf) write(h float64, txtStr string, link int, linkStr string) {
 			}
 			nl++
 		} else {
-			i++
+			i += size
 		}
 	}
 	// Last chunk
 	if i != j {
-		f.CellFormat(l/1000*f.fontSize, h, s[j:], "", 0, "", false, link, linkStr)
+		f.tabCellFormat(l/1000*f.fontSize, h, s[j:], "", 0, "L", false, link, linkStr, f.lMargin)
 	}
 }
 
@@ -2112,14 +3279,14 @@ func (f *Fpdf) imageOut(info *ImageInfoType, x, y, w, h float64, flow bool, link
 	}
 	// Flowing mode
 	if flow {
-		if f.y+h > f.pageBreakTrigger && !f.inHeader && !f.inFooter && f.acceptPageBreak() {
+		if f.y+h > f.pageBreakTrigger && !f.inHeader && !f.inFooter && f.acceptPageBreak() && !f.nextColumn() {
 			// Automatic page break
-			x2 := f.x
+			x2 := f.x - f.columnOrigin()
 			f.AddPageFormat(f.curOrientation, f.curPageSize)
 			if f.err != nil {
 				return
 			}
-			f.x = x2
+			f.x = x2 + f.columnOrigin()
 		}
 		y = f.y
 		f.y += h
@@ -2506,15 +3673,6 @@ func blankCount(str string) (count int) {
 	return
 }
 
-// Underline text
-func (f *Fpdf) dounderline(x, y float64, txt string) string {
-	up := float64(f.currentFont.Up)
-	ut := float64(f.currentFont.Ut)
-	w := f.GetStringWidth(txt) + f.ws*float64(blankCount(txt))
-	return sprintf("%.2f %.2f %.2f %.2f re f", x*f.k,
-		(f.h-(y-up/1000*f.fontSize))*f.k, w*f.k, -ut/1000*f.fontSizePt)
-}
-
 func bufEqual(buf []byte, str string) bool {
 	return string(buf[0:len(str)]) == str
 }
@@ -2793,8 +3951,24 @@ func (f *Fpdf) putstream(b []byte) {
 	f.out("endstream")
 }
 
+// silently calls fnc with the output to the document suppressed, unless it
+// is suppressed already. The current position and page breaks are not
+// affected.
+func (f *Fpdf) silently(fnc func()) {
+	if f.silent {
+		fnc()
+		return
+	}
+	f.silent = true
+	fnc()
+	f.silent = false
+}
+
 // Add a line to the document
 func (f *Fpdf) out(s string) {
+	if f.silent {
+		return
+	}
 	if f.state == 2 {
 		f.pages[f.page].WriteString(s)
 		f.pages[f.page].WriteString("\n")
@@ -2806,6 +3980,9 @@ func (f *Fpdf) out(s string) {
 
 // Add a buffered line to the document
 func (f *Fpdf) outbuf(b *bytes.Buffer) {
+	if f.silent {
+		return
+	}
 	if f.state == 2 {
 		f.pages[f.page].ReadFrom(b)
 		f.pages[f.page].WriteString("\n")
@@ -2932,10 +4109,18 @@ func (f *Fpdf) putfonts() {
 		f.newobj()
 		info.n = f.n
 		f.fontFiles[file] = info
-		font, err := ioutil.ReadFile(path.Join(f.fontpath, file))
-		if err != nil {
-			f.err = err
-			return
+		font := info.content
+		if font == nil {
+			dir := f.fontpath
+			if info.dir != "" {
+				dir = info.dir
+			}
+			var err error
+			font, err = ioutil.ReadFile(path.Join(dir, file))
+			if err != nil {
+				f.err = err
+				return
+			}
 		}
 		// dbg("font file [%s], ext [%s]", file, file[len(file)-2:])
 		compressed := file[len(file)-2:] == ".z"
@@ -2948,7 +4133,11 @@ func (f *Fpdf) putfonts() {
 		if compressed {
 			f.out("/Filter /FlateDecode")
 		}
-		f.outf("/Length1 %d", info.length1)
+		if info.subtype != "" {
+			f.outf("/Subtype /%s", info.subtype)
+		} else {
+			f.outf("/Length1 %d", info.length1)
+		}
 		if info.length2 > 0 {
 			f.outf("/Length2 %d /Length3 0", info.length2)
 		}
@@ -2973,12 +4162,17 @@ func (f *Fpdf) putfonts() {
 			}
 			f.out(">>")
 			f.out("endobj")
-		} else if tp == "Type1" || tp == "TrueType" {
+		} else if tp == "Type1" || tp == "TrueType" || tp == "OpenType" {
 			// Additional Type1 or TrueType/OpenType font
 			f.newobj()
 			f.out("<</Type /Font")
 			f.outf("/BaseFont /%s", name)
-			f.outf("/Subtype /%s", tp)
+			if tp == "OpenType" {
+				// PostScript outlines
+				f.out("/Subtype /Type1")
+			} else {
+				f.outf("/Subtype /%s", tp)
+			}
 			f.out("/FirstChar 32 /LastChar 255")
 			f.outf("/Widths %d 0 R", f.n+1)
 			f.outf("/FontDescriptor %d 0 R", f.n+2)
@@ -3013,12 +4207,24 @@ func (f *Fpdf) putfonts() {
 			s.printf("/StemV %d ", font.Desc.StemV)
 			s.printf("/MissingWidth %d ", font.Desc.MissingWidth)
 			var suffix string
-			if tp != "Type1" {
+			switch tp {
+			case "TrueType":
 				suffix = "2"
+			case "OpenType":
+				suffix = "3"
 			}
 			s.printf("/FontFile%s %d 0 R>>", suffix, f.fontFiles[font.File].n)
 			f.out(s.String())
 			f.out("endobj")
+		} else if tp == "UTF8" {
+			// Unicode TrueType font
+			f.putUTF8Font(font)
+			if f.err != nil {
+				return
+			}
+		} else if tp == "CID" {
+			// Standard CJK font
+			f.putCIDFont(font)
 		} else {
 			f.err = fmt.Errorf("unsupported font type: %s", tp)
 			return
@@ -3273,6 +4479,14 @@ func (f *Fpdf) putheader() {
 	if len(f.blendMap) > 0 && f.pdfVersion < "1.4" {
 		f.pdfVersion = "1.4"
 	}
+	for _, font := range f.fonts {
+		// OpenType font programs with PostScript outlines
+		if font.Tp == "OpenType" || (font.utf8 != nil && font.utf8.ttf.CFF) {
+			if f.pdfVersion < "1.6" {
+				f.pdfVersion = "1.6"
+			}
+		}
+	}
 	f.outf("%%PDF-%s", f.pdfVersion)
 }
 
@@ -34,6 +34,7 @@ const (
 	cnFontDir   = cnGofpdfDir + "/font"
 	cnImgDir    = cnGofpdfDir + "/image"
 	cnTextDir   = cnGofpdfDir + "/text"
+	cnHyphenDir = cnGofpdfDir + "/hyphen"
 )
 
 type nullWriter struct {
@@ -101,6 +102,10 @@ func textFile(fileStr string) string {
 	return filepath.Join(cnTextDir, fileStr)
 }
 
+func hyphenFile(fileStr string) string {
+	return filepath.Join(cnHyphenDir, fileStr)
+}
+
 // Convert 'ABCDEFG' to, for example, 'A,BCD,EFG'
 func strDelimit(str string, sepstr string, sepcount int) string {
 	pos := len(str) - sepcount
@@ -1052,6 +1057,55 @@ func ExampleFpdf_tutorial19() {
 	// Successfully generated pdf/tutorial19.pdf
 }
 
+// This example demonstrates that SplitLines() breaks lines at the same width
+// as MultiCell(). The text is slightly wider than the space within the cell
+// margins, so it is split into two lines.
+func ExampleFpdf_SplitLines() {
+	pdf := gofpdf.New("P", "pt", "A4", "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.AddPage()
+	str := "Hello World"
+	wd := pdf.GetStringWidth(str)
+	w := wd + 2*pdf.GetCellMargin() - 0.005
+	fmt.Printf("text %.3f, space %.3f\n", wd, w-2*pdf.GetCellMargin())
+	for _, line := range pdf.SplitLines([]byte(str), w) {
+		fmt.Printf("%q\n", line)
+	}
+	pdf.Close()
+	// Output:
+	// text 51.670, space 51.665
+	// "Hello"
+	// "World"
+}
+
+// This example compares the lines of a paragraph broken with the "first-fit"
+// algorithm, which fills each line in turn, with those chosen by the
+// "total-fit" algorithm, which spaces the lines of the paragraph more evenly.
+func ExampleFpdf_SplitLinesMode() {
+	pdf := gofpdf.New("P", "pt", "A4", "")
+	pdf.SetFont("Courier", "", 10)
+	pdf.AddPage()
+	str := "Lines are broken so that the spacing of the whole paragraph is even"
+	// Room for 28 characters of 6 points each
+	w := 28*6 + 2*pdf.GetCellMargin()
+	for _, modeStr := range []string{"first-fit", "total-fit"} {
+		fmt.Println(modeStr)
+		for _, line := range pdf.SplitLinesMode([]byte(str), w, modeStr) {
+			fmt.Printf("  %-30q %2d\n", line, len(line))
+		}
+	}
+	pdf.Close()
+	// Output:
+	// first-fit
+	//   "Lines are broken so that the" 28
+	//   "spacing of the whole"         20
+	//   "paragraph is even"            17
+	// total-fit
+	//   "Lines are broken so that"     24
+	//   "the spacing of the whole"     24
+	//   "paragraph is even"            17
+}
+
 // This example demonstrates how to render a simple path-only SVG image of the
 // type generated by the jSignature web control.
 func ExampleFpdf_tutorial20() {
@@ -1425,3 +1479,1158 @@ func ExampleFpdf_tutorial28() {
 	// Successfully generated pdf/tutorial28.pdf
 
 }
+
+// This example demonstrates the use of a Unicode TrueType font. Text in any
+// script supported by the font can be passed as UTF-8 directly. Only the
+// glyphs that are actually used are embedded in the document.
+func ExampleFpdf_tutorial29() {
+	pdf := gofpdf.New("P", "mm", "A4", cnFontDir)
+	pdf.AddUTF8Font("DejaVu", "", "DejaVuSans.ttf")
+	pdf.AddPage()
+	pdf.SetFont("DejaVu", "", 14)
+	pdf.CellFormat(0, 10, "Unicode TrueType font", "B", 1, "C", false, 0, "")
+	pdf.Ln(4)
+	for _, str := range []string{
+		"Polish: Zażółć gęślą jaźń.",
+		"Greek: Ξεσκεπάζω την ψυχοφθόρα βδελυγμία.",
+		"Russian: Съешь же ещё этих мягких французских булок, да выпей чаю.",
+		"Czech: Příliš žluťoučký kůň úpěl ďábelské ódy.",
+		"Turkish: Pijamalı hasta yağız şoföre çabucak güvendi.",
+	} {
+		pdf.MultiCell(0, 7, str, "", "J", false)
+		pdf.Ln(2)
+	}
+	pdf.Ln(4)
+	pdf.MultiCell(0, 7, "Lorem ipsum — «дружно» — Ἀθῆναι — ŁÓDŹ — "+
+		"justified text that wraps over more than one line demonstrates that "+
+		"word spacing is applied to Unicode text as well as to text that uses "+
+		"one of the standard fonts.", "1", "J", false)
+	pdf.Ln(4)
+	pdf.Write(7, "Write() also accepts UTF-8 text: λόγος, słowo, слово. ")
+	pdf.SetTextColor(0, 0, 200)
+	pdf.WriteLinkString(7, "Ссылка", "https://github.com/jung-kurt/gofpdf")
+	pdf.OutputAndClose(docWriter(pdf, 29))
+	// Output:
+	// Successfully generated pdf/tutorial29.pdf
+}
+
+// This example demonstrates OpenType fonts with PostScript (CFF) outlines.
+// Like TrueType fonts, they can be used with a definition file generated by
+// MakeFont() or directly as Unicode fonts with AddUTF8Font().
+func ExampleFpdf_tutorial30() {
+	gofpdf.MakeFont(fontFile("CFFTest.otf"), fontFile("cp1252.map"), cnFontDir, nil, true)
+	pdf := gofpdf.New("P", "mm", "A4", cnFontDir)
+	pdf.AddFont("CFFTest", "", "CFFTest.json")
+	pdf.AddUTF8Font("FontAwesome", "", "FontAwesome.otf")
+	pdf.AddUTF8Font("DejaVu", "", "DejaVuSans.ttf")
+	pdf.AddPage()
+	pdf.SetFont("DejaVu", "", 14)
+	pdf.Write(8, "Glyphs of an OpenType font with encoding:\n")
+	pdf.SetFont("CFFTest", "", 36)
+	pdf.CellFormat(0, 20, "Q10 01Q", "1", 1, "C", false, 0, "")
+	pdf.Ln(6)
+	pdf.SetFont("DejaVu", "", 14)
+	pdf.Write(8, "Icons of an OpenType font with Unicode encoding:\n")
+	for _, icon := range []struct {
+		r       rune
+		nameStr string
+	}{
+		{'', "Home"},
+		{'', "Mail"},
+		{'', "Phone"},
+		{'', "Heart"},
+		{'', "Star"},
+	} {
+		pdf.SetFont("FontAwesome", "", 24)
+		pdf.CellFormat(12, 12, string(icon.r), "", 0, "C", false, 0, "")
+		pdf.SetFont("DejaVu", "", 14)
+		pdf.CellFormat(0, 12, icon.nameStr, "", 1, "L", false, 0, "")
+	}
+	pdf.OutputAndClose(docWriter(pdf, 30))
+	// Output:
+	// Successfully generated pdf/tutorial30.pdf
+}
+
+// This example demonstrates pair kerning. The space between certain pairs of
+// characters, for example "AV" and "To", is adjusted as specified by the
+// font. Kerning affects the measurement of text as well as its output.
+func ExampleFpdf_tutorial31() {
+	const str = "AVATAR WAVE Tower Yacht LT AWAY"
+	pdf := gofpdf.New("P", "mm", "A4", cnFontDir)
+	pdf.AddUTF8Font("DejaVu", "", "DejaVuSans.ttf")
+	pdf.AddPage()
+	pdf.SetFont("DejaVu", "", 28)
+	for _, kern := range []bool{false, true} {
+		pdf.SetKerning(kern)
+		pdf.SetFont("DejaVu", "", 10)
+		pdf.CellFormat(0, 8, fmt.Sprintf("Kerning enabled: %v", kern), "", 1, "L", false, 0, "")
+		pdf.SetFont("DejaVu", "", 28)
+		wd := pdf.GetStringWidth(str)
+		pdf.CellFormat(wd, 14, str, "1", 1, "L", false, 0, "")
+		pdf.Ln(4)
+	}
+	pdf.SetFont("DejaVu", "", 12)
+	pdf.MultiCell(0, 6, "VAT, Tax and Toll: LAWYER'S AFFIDAVIT. "+
+		"With kerning enabled, line breaking and justification take the "+
+		"adjusted widths into account. WAVY AVENUE, Yvonne Tyler, \"Tovarich\".", "1", "J", false)
+	pdf.OutputAndClose(docWriter(pdf, 31))
+	// Output:
+	// Successfully generated pdf/tutorial31.pdf
+}
+
+// This example demonstrates ligatures. When they are enabled, sequences of
+// characters such as "fi" and "ffl" are replaced by the single glyphs that the
+// font provides for them. Text that is copied from the document still reads
+// as the original characters.
+func ExampleFpdf_tutorial32() {
+	const str = "fish and waffles, a flat office offering efficient staff"
+	pdf := gofpdf.New("P", "mm", "A4", cnFontDir)
+	pdf.AddUTF8Font("DejaVu", "", "DejaVuSans.ttf")
+	pdf.AddPage()
+	for _, opt := range []struct {
+		std, disc bool
+		label     string
+	}{
+		{false, false, "No ligatures"},
+		{true, false, "Standard ligatures"},
+		{true, true, "Standard and discretionary ligatures"},
+	} {
+		pdf.SetLigatures(opt.std, opt.disc)
+		pdf.SetFont("DejaVu", "", 10)
+		pdf.CellFormat(0, 8, opt.label, "", 1, "L", false, 0, "")
+		pdf.SetFont("DejaVu", "", 20)
+		pdf.MultiCell(0, 10, str, "", "L", false)
+		pdf.Ln(4)
+	}
+	pdf.OutputAndClose(docWriter(pdf, 32))
+	// Output:
+	// Successfully generated pdf/tutorial32.pdf
+}
+
+// This example demonstrates right-to-left text. Arabic and Hebrew are
+// reordered with the Unicode bidirectional algorithm and Arabic letters are
+// joined.
+func ExampleFpdf_tutorial33() {
+	pdf := gofpdf.New("P", "mm", "A4", cnFontDir)
+	pdf.AddUTF8Font("DejaVu", "", "DejaVuSans.ttf")
+	pdf.AddPage()
+	pdf.SetFont("DejaVu", "", 10)
+	pdf.CellFormat(0, 8, "Left-to-right paragraphs with embedded right-to-left text", "", 1, "L", false, 0, "")
+	pdf.SetFont("DejaVu", "", 14)
+	pdf.MultiCell(0, 8, "The Arabic word for peace is سلام and the Hebrew word is שלום.", "", "L", false)
+	pdf.MultiCell(0, 8, "Version 2 (العربية 123)", "", "L", false)
+	pdf.Ln(4)
+	pdf.SetRightToLeft(true)
+	pdf.SetFont("DejaVu", "", 10)
+	pdf.CellFormat(0, 8, "Right-to-left paragraphs", "", 1, "L", false, 0, "")
+	pdf.SetFont("DejaVu", "", 14)
+	pdf.MultiCell(0, 8, "مرحبا بالعالم! هذا نص عربي مع أرقام 2015 وكلمة English في الوسط، "+
+		"ويستمر النص على أكثر من سطر واحد لإظهار كيفية تقسيم الأسطر وضبطها.", "1", "", false)
+	pdf.Ln(4)
+	pdf.MultiCell(0, 8, "שלום עולם (Hello world) - 3.14", "", "", false)
+	pdf.MultiCell(80, 8, "لا إله إلا الله", "1", "C", false)
+	pdf.OutputAndClose(docWriter(pdf, 33))
+	// Output:
+	// Successfully generated pdf/tutorial33.pdf
+}
+
+// This example demonstrates font fallback. Characters that the Calligrapher
+// font lacks are taken from DejaVu Sans, and icons from Font Awesome.
+func ExampleFpdf_tutorial34() {
+	const str = "Customers: Zoë Ψαράκη, Алексей Чехов and José Müller \uf004 \uf09b (2 → 3 ≈ π)"
+	pdf := gofpdf.New("P", "mm", "A4", cnFontDir)
+	pdf.AddUTF8Font("Calligrapher", "", "calligra.ttf")
+	pdf.AddUTF8Font("DejaVu", "", "DejaVuSans.ttf")
+	pdf.AddUTF8Font("FontAwesome", "", "FontAwesome.otf")
+	pdf.AddPage()
+	pdf.SetFont("Calligrapher", "", 16)
+	pdf.CellFormat(0, 10, "Without fallback", "", 1, "L", false, 0, "")
+	pdf.MultiCell(0, 10, str, "1", "L", false)
+	pdf.Ln(6)
+	pdf.SetFontFallback("Calligrapher", "DejaVu", "FontAwesome")
+	pdf.CellFormat(0, 10, "With fallback", "", 1, "L", false, 0, "")
+	pdf.MultiCell(0, 10, str, "1", "L", false)
+	pdf.Ln(6)
+	pdf.Write(10, str)
+	pdf.OutputAndClose(docWriter(pdf, 34))
+	// Output:
+	// Successfully generated pdf/tutorial34.pdf
+}
+
+// This example demonstrates the text state: rendering modes, character and
+// word spacing, horizontal scaling and baseline rise.
+func ExampleFpdf_tutorial35() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 28)
+	pdf.SetDrawColor(0, 0, 160)
+	pdf.SetTextColor(255, 220, 0)
+	pdf.SetLineWidth(0.3)
+	for _, mode := range []struct {
+		mode  int
+		label string
+	}{
+		{gofpdf.CnTextRenderFill, "Fill"},
+		{gofpdf.CnTextRenderStroke, "Stroke"},
+		{gofpdf.CnTextRenderFillStroke, "Fill and stroke"},
+		{gofpdf.CnTextRenderInvisible, "Invisible"},
+	} {
+		pdf.SetTextRenderingMode(mode.mode)
+		pdf.CellFormat(0, 12, mode.label, "1", 1, "C", false, 0, "")
+	}
+	pdf.SetTextRenderingMode(gofpdf.CnTextRenderFill)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.Ln(4)
+	const str = "The quick brown fox jumps over the lazy dog. "
+	for _, opt := range []struct {
+		cs, ws, scale float64
+		label         string
+	}{
+		{0, 0, 100, "Normal"},
+		{0.5, 0, 100, "Character spacing 0.5 mm"},
+		{0, 3, 100, "Word spacing 3 mm"},
+		{0, 0, 75, "Horizontal scaling 75%"},
+		{-0.2, 1, 120, "Combined"},
+	} {
+		pdf.SetCharSpacing(opt.cs)
+		pdf.SetWordSpacing(opt.ws)
+		pdf.SetHorizontalScaling(opt.scale)
+		pdf.MultiCell(0, 6, opt.label+": "+str+str, "1", "J", false)
+		pdf.Ln(2)
+	}
+	pdf.SetCharSpacing(0)
+	pdf.SetWordSpacing(0)
+	pdf.SetHorizontalScaling(100)
+	pdf.Write(8, "Einstein wrote E = mc")
+	pdf.SetFontSize(8)
+	pdf.SetTextRise(2)
+	pdf.Write(8, "2")
+	pdf.SetFontSize(12)
+	pdf.SetTextRise(0)
+	pdf.Write(8, " and water is H")
+	pdf.SetFontSize(8)
+	pdf.SetTextRise(-1)
+	pdf.Write(8, "2")
+	pdf.SetFontSize(12)
+	pdf.SetTextRise(0)
+	pdf.Write(8, "O.")
+	pdf.Ln(10)
+	pdf.SetTextRenderingMode(gofpdf.CnTextRenderStroke)
+	pdf.SetFont("Times", "B", 40)
+	pdf.Text(20, pdf.GetY()+15, "Outlined text")
+	pdf.OutputAndClose(docWriter(pdf, 35))
+	// Output:
+	// Successfully generated pdf/tutorial35.pdf
+}
+
+// This example demonstrates automatic hyphenation of justified text in narrow
+// columns, with English and German patterns.
+func ExampleFpdf_tutorial36() {
+	const (
+		colWd  = 45.0
+		gutter = 8.0
+		lineHt = 4.5
+	)
+	enStr := "Typesetting justified paragraphs in narrow columns frequently " +
+		"produces conspicuous gaps between words, particularly when the " +
+		"vocabulary includes considerably long technical terminology such as " +
+		"internationalization, characteristically or incomprehensibility."
+	deStr := "Die Donaudampfschifffahrtsgesellschaft veröffentlicht regelmäßig " +
+		"Informationen über Fahrplanänderungen, Sicherheitsbestimmungen und " +
+		"Reiseangebote für Urlaubsgäste in verschiedenen Sprachen."
+	en, err := gofpdf.HyphenatorFromFile(hyphenFile("hyph-en-us.tex"), 5, 2, 3)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	de, err := gofpdf.HyphenatorFromFile(hyphenFile("hyph-de-1996.tex"), 5, 2, 2)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	pdf := gofpdf.New("P", "mm", "A4", cnFontDir)
+	pdf.AddUTF8Font("DejaVu", "", "DejaVuSans.ttf")
+	pdf.AddPage()
+	column := func(col int, label, str string) {
+		x := pdf.GetX() + float64(col)*(colWd+gutter)
+		y := pdf.GetY()
+		pdf.SetXY(x, y)
+		pdf.SetFont("DejaVu", "", 8)
+		pdf.CellFormat(colWd, 6, label, "", 2, "L", false, 0, "")
+		pdf.SetFont("DejaVu", "", 10)
+		pdf.SetLeftMargin(x)
+		pdf.MultiCell(colWd, lineHt, str, "", "J", false)
+		pdf.SetLeftMargin(10)
+		pdf.SetXY(10, y)
+	}
+	row := func(str string, fnc func([]rune) []int) {
+		y := pdf.GetY()
+		pdf.SetHyphenFunc(nil)
+		column(0, "Without hyphenation", str)
+		pdf.SetHyphenFunc(fnc)
+		column(1, "With hyphenation", str)
+		lines := pdf.SplitLines([]byte(str), colWd)
+		pdf.SetY(y + 6 + float64(len(lines))*lineHt + 10)
+	}
+	row(enStr, en)
+	row(deStr, de)
+	pdf.SetFont("DejaVu", "", 10)
+	pdf.SetHyphenFunc(en)
+	pdf.Write(lineHt, "Write() breaks lines with hyphens as well: "+enStr+" "+enStr)
+	pdf.OutputAndClose(docWriter(pdf, 36))
+	// Output:
+	// Successfully generated pdf/tutorial36.pdf
+}
+
+// This example compares the first-fit and total-fit line breaking algorithms
+// for justified paragraphs, with and without hyphenation.
+func ExampleFpdf_tutorial37() {
+	const (
+		colWd  = 60.0
+		gutter = 10.0
+		lineHt = 5.0
+	)
+	txtStr := "Breaking a paragraph into lines one line at a time sometimes " +
+		"leaves a line with wide gaps between its words, because the next " +
+		"word did not quite fit. Considering all of the lines of the " +
+		"paragraph together, the total-fit algorithm spreads the available " +
+		"space more evenly and avoids such loose lines whenever possible.\n" +
+		"Explicit line feeds start a new paragraph, and the last line of " +
+		"each paragraph is set without stretching."
+	hyphenate, err := gofpdf.HyphenatorFromFile(hyphenFile("hyph-en-us.tex"), 5, 2, 3)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	column := func(col int, label, modeStr string) {
+		x := 10 + float64(col)*(colWd+gutter)
+		y := pdf.GetY()
+		pdf.SetXY(x, y)
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.CellFormat(colWd, 6, label, "", 2, "L", false, 0, "")
+		pdf.SetFont("Times", "", 11)
+		pdf.SetLeftMargin(x)
+		pdf.MultiCellMode(colWd, lineHt, txtStr, "1", "J", false, modeStr)
+		pdf.SetLeftMargin(10)
+		pdf.SetXY(10, y)
+	}
+	row := func(fnc func([]rune) []int) {
+		y := pdf.GetY()
+		pdf.SetHyphenFunc(fnc)
+		column(0, "First-fit", "first-fit")
+		column(1, "Total-fit", "total-fit")
+		pdf.SetFont("Times", "", 11)
+		n := len(pdf.SplitLinesMode([]byte(txtStr), colWd, "first-fit"))
+		if m := len(pdf.SplitLinesMode([]byte(txtStr), colWd, "total-fit")); m > n {
+			n = m
+		}
+		pdf.SetY(y + 6 + float64(n)*lineHt + 10)
+	}
+	row(nil)
+	row(hyphenate)
+	pdf.OutputAndClose(docWriter(pdf, 37))
+	// Output:
+	// Successfully generated pdf/tutorial37.pdf
+}
+
+// This example demonstrates paragraphs of styled text runs that are measured
+// and then drawn in boxes of fixed width.
+func ExampleFpdf_tutorial38() {
+	const (
+		boxWd  = 85.0
+		margin = 3.0
+	)
+	pdf := gofpdf.New("P", "mm", "A4", cnFontDir)
+	pdf.AddFont("Calligrapher", "", "calligra.json")
+	pdf.AddPage()
+	pdf.SetFont("Times", "", 11)
+	link := pdf.AddLink()
+	paragraph := func(alignStr string) (rt *gofpdf.RichTextType) {
+		rt = pdf.RichTextNew(alignStr)
+		rt.AddText("", "", 0, "A paragraph can mix ")
+		rt.AddText("", "B", 0, "bold, ")
+		rt.AddText("", "I", 0, "italic and ")
+		rt.AddText("", "U", 0, "underlined runs of text")
+		rt.AddText("", "", 0, " with ")
+		rt.AddText("Calligrapher", "", 16, "other fonts")
+		rt.AddText("", "", 0, ", ")
+		rt.AddText("Helvetica", "", 7, "small print")
+		rt.AddText("", "", 0, " and ")
+		rt.AddRun(gofpdf.RichTextRunType{Str: "colors", Size: 14, ClrR: 192, ClrG: 32, ClrB: 32})
+		rt.AddText("", "", 0, ". Runs share a common baseline, and each line is as high "+
+			"as its largest font. ")
+		rt.AddRun(gofpdf.RichTextRunType{Str: "Links", StyleStr: "U", ClrB: 160, Link: link})
+		rt.AddText("", "", 0, " and ")
+		rt.AddRun(gofpdf.RichTextRunType{Str: "external links", StyleStr: "U", ClrB: 160,
+			LinkStr: "https://github.com/jung-kurt/gofpdf"})
+		rt.AddText("", "", 0, " work as well.\nA line feed starts a new line.")
+		return
+	}
+	y := pdf.GetY()
+	rowHt := 0.0
+	for j, alignStr := range []string{"L", "J", "C", "R"} {
+		x := 10 + float64(j%2)*(boxWd+10)
+		if j == 2 {
+			y += rowHt + 10
+		}
+		rt := paragraph(alignStr)
+		ht := rt.Height(boxWd-2*margin) + 2*margin
+		pdf.Rect(x, y, boxWd, ht, "D")
+		rt.Draw(x+margin, y+margin, boxWd-2*margin)
+		if ht > rowHt {
+			rowHt = ht
+		}
+	}
+	pdf.SetY(y + rowHt + 20)
+	pdf.SetLink(link, -1, -1)
+	pdf.Write(5, "This is the destination of the internal link.")
+	pdf.OutputAndClose(docWriter(pdf, 38))
+	// Output:
+	// Successfully generated pdf/tutorial38.pdf
+}
+
+// This example demonstrates name badges with boxes of fixed size in which the
+// font size is reduced until the text fits.
+func ExampleFpdf_tutorial39() {
+	const (
+		boxWd = 60.0
+		boxHt = 25.0
+	)
+	names := []string{
+		"Ann Lee",
+		"Maximilian Alexander von Hohenstein",
+		"Dr. Bartholomew Montgomery-Fitzgerald, Chief Executive Officer",
+		"The International Association of Professional Typesetters, " +
+			"Bookbinders and Allied Printing Trades, Regional Chapter Seven, " +
+			"Annual General Meeting of Members and Invited Guests",
+	}
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.SetFillColor(230, 240, 255)
+	for row, alignStr := range []string{"CT", "CM", "CB"} {
+		for col, name := range names {
+			x := 10 + float64(col%3)*(boxWd+5)
+			y := 15 + float64(row*2+col/3)*(boxHt+10)
+			pdf.SetXY(x, y)
+			sizePt, overflow := pdf.MultiCellFit(boxWd, boxHt, name, "1", alignStr, true, 10, 24)
+			pdf.SetFont("Helvetica", "", 7)
+			pdf.SetXY(x, y+boxHt)
+			str := fmt.Sprintf("%s: %.1f pt", alignStr, sizePt)
+			if overflow {
+				str += ", overflow"
+			}
+			pdf.CellFormat(boxWd, 4, str, "", 0, "L", false, 0, "")
+			pdf.SetFont("Helvetica", "B", 12)
+		}
+	}
+	pdf.OutputAndClose(docWriter(pdf, 39))
+	// Output:
+	// Successfully generated pdf/tutorial39.pdf
+}
+
+// This example demonstrates the treatment of text that is wider than its cell
+// in a table.
+func ExampleFpdf_tutorial40() {
+	const (
+		colWd = 38.0
+		rowHt = 8.0
+	)
+	cells := []string{"Short", "A considerably longer description", "Right aligned overflow"}
+	pdf := gofpdf.New("P", "mm", "A4", cnFontDir)
+	pdf.AddUTF8Font("DejaVu", "", "DejaVuSans.ttf")
+	pdf.AddPage()
+	pdf.SetFillColor(240, 240, 240)
+	for _, familyStr := range []string{"Helvetica", "DejaVu"} {
+		for _, modeStr := range []string{"visible", "clip", "ellipsis", "scale", "shrink"} {
+			pdf.SetCellOverflow("visible")
+			pdf.SetFont("Helvetica", "B", 9)
+			pdf.CellFormat(colWd, rowHt, familyStr+", "+modeStr, "1", 0, "L", true, 0, "")
+			pdf.SetCellOverflow(modeStr)
+			pdf.SetFont(familyStr, "", 11)
+			for j, str := range cells {
+				alignStr := "L"
+				if j == 2 {
+					alignStr = "R"
+				}
+				pdf.CellFormat(colWd, rowHt, str, "1", 0, alignStr, false, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+		pdf.Ln(5)
+	}
+	pdf.OutputAndClose(docWriter(pdf, 40))
+	// Output:
+	// Successfully generated pdf/tutorial40.pdf
+}
+
+// This example demonstrates the standard CJK fonts, which are not embedded in
+// the document, in horizontal and vertical writing.
+func ExampleFpdf_tutorial41() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddCIDFont("Japanese", "", "Japan1", false)
+	pdf.AddCIDFont("Japanese", "B", "Japan1", false)
+	pdf.AddCIDFont("JapaneseV", "", "Japan1", true)
+	pdf.AddCIDFont("SimplifiedChinese", "", "GB1", false)
+	pdf.AddCIDFont("TraditionalChinese", "", "CNS1", false)
+	pdf.AddCIDFont("Korean", "", "Korea1", false)
+	pdf.AddPage()
+	pdf.SetFont("Japanese", "B", 14)
+	pdf.CellFormat(0, 10, "出荷案内書 (Shipping notice)", "B", 1, "L", false, 0, "")
+	pdf.Ln(3)
+	pdf.SetFont("Japanese", "", 11)
+	pdf.MultiCell(90, 6, "お届け先：東京都千代田区丸の内一丁目1番1号。"+
+		"品名：電子部品、数量：120個。ご不明な点がございましたら、"+
+		"お気軽にお問い合わせください。ｶﾀｶﾅ (half-width)", "1", "L", false)
+	pdf.Ln(3)
+	pdf.SetFont("SimplifiedChinese", "", 11)
+	pdf.MultiCell(90, 6, "收货地址：北京市朝阳区建国路88号。货物名称：电子元件，数量：120件。", "1", "L", false)
+	pdf.Ln(3)
+	pdf.SetFont("TraditionalChinese", "", 11)
+	pdf.MultiCell(90, 6, "收貨地址：臺北市信義區市府路1號。貨物名稱：電子零件，數量：120件。", "1", "L", false)
+	pdf.Ln(3)
+	pdf.SetFont("Korean", "", 11)
+	pdf.MultiCell(90, 6, "배송지: 서울특별시 중구 세종대로 110. 품명: 전자 부품, 수량: 120개.", "1", "L", false)
+	// Vertical writing
+	pdf.SetFont("JapaneseV", "", 14)
+	pdf.Text(190, 30, "縦書きの見出し")
+	pdf.SetFont("JapaneseV", "", 11)
+	pdf.SetXY(110, 30)
+	pdf.MultiCellVertical(7, 90, "吾輩は猫である。名前はまだ無い。\n"+
+		"どこで生れたかとんと見当がつかぬ。何でも薄暗いじめじめした所で"+
+		"ニャーニャー泣いていた事だけは記憶している。", "1", "T", false)
+	pdf.SetXY(110, 130)
+	pdf.MultiCellVertical(10, 40, "中央揃え\n縦書き", "1", "M", false)
+	pdf.OutputAndClose(docWriter(pdf, 41))
+	// Output:
+	// Successfully generated pdf/tutorial41.pdf
+}
+
+// This example demonstrates font metrics and ink bounding boxes, which are
+// used here to align text of different fonts and sizes precisely.
+func ExampleFpdf_tutorial42() {
+	pdf := gofpdf.New("P", "mm", "A4", cnFontDir)
+	pdf.AddUTF8Font("DejaVu", "", "DejaVuSans.ttf")
+	pdf.AddPage()
+	pdf.SetLineWidth(0.1)
+	// Metrics of several fonts, drawn as lines across a sample of text
+	x, y := 15.0, 40.0
+	for _, font := range []struct {
+		familyStr string
+		size      float64
+	}{{"Times", 36}, {"Helvetica", 32}, {"Courier", 28}, {"DejaVu", 28}} {
+		pdf.SetFont(font.familyStr, "", font.size)
+		m := pdf.GetFontMetrics()
+		str := "Hxgp"
+		wd := pdf.GetStringWidth(str)
+		// Ascent, cap height, x-height, baseline and descent
+		for _, line := range []struct {
+			v       float64
+			r, g, b int
+		}{
+			{m.Ascent, 200, 0, 0},
+			{m.CapHeight, 0, 128, 0},
+			{m.XHeight, 0, 0, 200},
+			{0, 0, 0, 0},
+			{m.Descent, 200, 0, 200},
+		} {
+			pdf.SetDrawColor(line.r, line.g, line.b)
+			pdf.Line(x-2, y-line.v, x+wd+2, y-line.v)
+		}
+		pdf.Text(x, y, str)
+		// Ink bounding box
+		bx, by, bw, bh := pdf.GetStringBounds(str)
+		pdf.SetDrawColor(255, 160, 0)
+		pdf.Rect(x+bx, y+by, bw, bh, "D")
+		pdf.SetFont("Helvetica", "", 7)
+		pdf.Text(x, y+12, font.familyStr)
+		x += wd + 12
+	}
+	// Baselines of different fonts and sizes aligned in a row of cells
+	pdf.SetDrawColor(0, 0, 0)
+	y = 80
+	x = 15
+	rowHt := 20.0
+	pdf.Rect(x, y, 180, rowHt, "D")
+	// Place the baseline so that the tallest capitals are centered
+	pdf.SetFont("Times", "B", 36)
+	capHt := pdf.GetFontMetrics().CapHeight
+	baseline := y + (rowHt+capHt)/2
+	for _, font := range []struct {
+		familyStr, styleStr string
+		size                float64
+		str                 string
+	}{{"Times", "B", 36, "Total "}, {"Helvetica", "", 14, "amount due "},
+		{"Courier", "", 20, "EUR 1,250.00 "}, {"DejaVu", "", 10, "(incl. VAT)"}} {
+		pdf.SetFont(font.familyStr, font.styleStr, font.size)
+		pdf.Text(x+2, baseline, font.str)
+		x += pdf.GetStringWidth(font.str)
+	}
+	pdf.SetDrawColor(200, 0, 0)
+	pdf.Line(15, baseline, 195, baseline)
+	// Text centered vertically in a box by its ink rather than its font size
+	y = 110
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.SetFont("Helvetica", "B", 28)
+	for j, str := range []string{"ABC", "xyz", "gjpq"} {
+		bx := 15 + float64(j)*60
+		pdf.Rect(bx, y, 55, 20, "D")
+		ix, iy, iw, ih := pdf.GetStringBounds(str)
+		pdf.Text(bx+(55-iw)/2-ix, y+(20-ih)/2-iy, str)
+	}
+	pdf.OutputAndClose(docWriter(pdf, 42))
+	// Output:
+	// Successfully generated pdf/tutorial42.pdf
+}
+
+// This example demonstrates strikethrough and overline text styles and the
+// control of the color, thickness, offset and pattern of text decorations.
+func ExampleFpdf_tutorial43() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 14)
+	for _, styleStr := range []string{"U", "S", "O", "US", "UO"} {
+		pdf.SetFont("", styleStr, 0)
+		pdf.CellFormat(0, 9, "Style \""+styleStr+"\" applied to a cell", "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+	// Decoration patterns, with a color that differs from the text
+	pdf.SetTextDecorationColor(200, 0, 0)
+	for _, dec := range []struct {
+		styleStr          string
+		thickness, offset float64
+	}{{"solid", 0, 0}, {"double", 0.2, 0}, {"dashed", 0.4, 0.5}, {"solid", 0.6, 1}} {
+		pdf.SetTextDecorationStyle(dec.styleStr, dec.thickness, dec.offset)
+		pdf.SetFont("", "U", 0)
+		pdf.CellFormat(0, 9, fmt.Sprintf("Underline %s, thickness %.1f, offset %.1f", dec.styleStr,
+			dec.thickness, dec.offset), "", 1, "L", false, 0, "")
+	}
+	pdf.SetTextDecorationStyle("double", 0, 0)
+	pdf.SetFont("", "S", 0)
+	pdf.CellFormat(0, 9, "Double strikethrough", "", 1, "L", false, 0, "")
+	pdf.SetTextDecorationStyle("solid", 0, 0)
+	pdf.SetTextDecorationColor(-1, 0, 0)
+	pdf.Ln(4)
+	// Decorations wrap with the text of MultiCell() and Write()
+	pdf.SetFont("Times", "S", 12)
+	pdf.MultiCell(120, 6, "This paragraph, struck through from start to finish, "+
+		"is justified within a cell so that the width of each line of decoration "+
+		"includes the stretched spaces.", "", "J", false)
+	pdf.Ln(4)
+	pdf.SetFont("Times", "", 12)
+	pdf.Write(6, "Running text can be ")
+	pdf.SetFont("", "O", 0)
+	pdf.Write(6, "overlined")
+	pdf.SetFont("", "", 0)
+	pdf.Write(6, " as well as ")
+	pdf.SetTextColor(0, 0, 160)
+	pdf.SetFont("", "US", 0)
+	pdf.Write(6, "underlined and struck through")
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("", "", 0)
+	pdf.Write(6, ".")
+	pdf.Ln(12)
+	// Strikethrough tags of HTMLBasic
+	html := pdf.HTMLBasicNew()
+	html.Write(6, "The price was <s>$40</s> <del>$30</del> <strike>$25</strike> "+
+		"<b>$20</b>, and <u>underlined <i>text</i></u> is unchanged.")
+	pdf.OutputAndClose(docWriter(pdf, 43))
+	// Output:
+	// Successfully generated pdf/tutorial43.pdf
+}
+
+// This example demonstrates the registration of the fonts in a directory and
+// their selection by family and style without individual calls to AddFont().
+func ExampleFpdf_tutorial44() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.RegisterFontDirectory(cnFontDir)
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 10, "Registered font families", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	for _, familyStr := range pdf.RegisteredFontFamilies() {
+		var styles []string
+		for _, styleStr := range pdf.RegisteredFontStyles(familyStr) {
+			if styleStr == "" {
+				styleStr = "regular"
+			}
+			styles = append(styles, styleStr)
+		}
+		pdf.CellFormat(60, 7, familyStr, "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 7, strings.Join(styles, ", "), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(6)
+	// Family names are matched without regard to case or spaces
+	pdf.SetFont("DejaVuSans", "", 16)
+	pdf.CellFormat(0, 10, "DejaVu Sans: Ça coûte 10 € — Ελληνικά", "", 1, "L", false, 0, "")
+	pdf.SetFont("calligrapher", "", 22)
+	pdf.CellFormat(0, 12, "Calligrapher, selected by family name", "", 1, "L", false, 0, "")
+	pdf.OutputAndClose(docWriter(pdf, 44))
+	// Output:
+	// Successfully generated pdf/tutorial44.pdf
+}
+
+// This example demonstrates fonts that are loaded from memory, as they would
+// be when embedded in an application, without font definition files.
+func ExampleFpdf_tutorial45() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	// The bytes would typically come from go:embed rather than from files
+	calligra, err := ioutil.ReadFile(filepath.Join(cnFontDir, "calligra.ttf"))
+	if err == nil {
+		pdf.AddFontFromBytes("Calligrapher", "", calligra, nil)
+	}
+	dejaVu, err := ioutil.ReadFile(filepath.Join(cnFontDir, "DejaVuSans.ttf"))
+	if err == nil {
+		pdf.AddUTF8FontFromBytes("DejaVu", "", dejaVu)
+	}
+	cp1251, err := ioutil.ReadFile(filepath.Join(cnFontDir, "cp1251.map"))
+	if err == nil {
+		pdf.AddFontFromBytes("DejaVuCyr", "", dejaVu, cp1251)
+	}
+	tr, _ := gofpdf.UnicodeTranslator(strings.NewReader(string(cp1251)))
+	pdf.AddPage()
+	pdf.SetFont("Calligrapher", "", 24)
+	pdf.CellFormat(0, 14, "Calligrapher with the cp1252 encoding", "", 1, "L", false, 0, "")
+	pdf.SetFont("DejaVu", "", 16)
+	pdf.CellFormat(0, 12, "DejaVu Sans in Unicode: Ελληνικά, Русский, Français", "", 1, "L", false, 0, "")
+	pdf.SetFont("DejaVuCyr", "", 16)
+	pdf.CellFormat(0, 12, tr("DejaVu Sans with the cp1251 encoding: Русский текст"), "", 1, "L", false, 0, "")
+	pdf.OutputAndClose(docWriter(pdf, 45))
+	// Output:
+	// Successfully generated pdf/tutorial45.pdf
+}
+
+// This example demonstrates a font that is loaded from the contents of a font
+// definition file and a compressed font file. Go source generated by the
+// makefont utility with the --pkg option compiles these contents into an
+// application and loads the font in this way.
+func ExampleFpdf_tutorial46() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	definition, err := ioutil.ReadFile(filepath.Join(cnFontDir, "calligra.json"))
+	if err == nil {
+		var compressedFont []byte
+		compressedFont, err = ioutil.ReadFile(filepath.Join(cnFontDir, "calligra.z"))
+		if err == nil {
+			pdf.AddFontFromDefinition("Calligrapher", "", definition, compressedFont)
+		}
+	}
+	if err != nil {
+		pdf.SetError(err)
+	}
+	pdf.AddPage()
+	pdf.SetFont("Calligrapher", "", 32)
+	pdf.CellFormat(0, 16, "A font compiled into the application", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.MultiCell(0, 6, "Run makefont with the --pkg option to generate a Go source file "+
+		"that contains the font definition and the compressed font, together with a "+
+		"function that calls AddFontFromDefinition(). No font files are needed when "+
+		"the document is generated.", "", "L", false)
+	pdf.OutputAndClose(docWriter(pdf, 46))
+	// Output:
+	// Successfully generated pdf/tutorial46.pdf
+}
+
+// This example demonstrates text drawn as vector outlines, which does not
+// require the font to be embedded, and the outlines used as a clipping path.
+func ExampleFpdf_tutorial47() {
+	pdf := gofpdf.New("P", "mm", "A4", cnFontDir)
+	pdf.AddUTF8Font("DejaVu", "", "DejaVuSans.ttf")
+	pdf.AddPage()
+	pdf.SetFont("DejaVu", "", 54)
+	// Filled and stroked outlines
+	pdf.SetFillColor(30, 90, 160)
+	pdf.SetDrawColor(200, 40, 40)
+	pdf.SetLineWidth(0.6)
+	pdf.TextOutline(15, 40, "Signage", "FD")
+	pdf.SetLineWidth(0.3)
+	pdf.TextOutline(15, 70, "Outline ß€Ω", "D")
+	// Outlines follow the current transformation
+	pdf.TransformBegin()
+	pdf.TransformRotate(12, 150, 100)
+	pdf.SetFillColor(0, 140, 70)
+	pdf.TextOutline(110, 100, "Tilted", "F")
+	pdf.TransformEnd()
+	// A gradient seen through the outlines of the text
+	pdf.SetFontSize(80)
+	pdf.ClipTextOutline(15, 160, "Logo", true)
+	pdf.LinearGradient(15, 120, 180, 50, 250, 200, 0, 180, 0, 90, 0, 0, 1, 1)
+	pdf.ClipEnd()
+	pdf.OutputAndClose(docWriter(pdf, 47))
+	// Output:
+	// Successfully generated pdf/tutorial47.pdf
+}
+
+// This example demonstrates the measurement of text without output, which is
+// used here to keep paragraphs from being split across pages.
+func ExampleFpdf_tutorial48() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Times", "", 12)
+	pdf.AddPage()
+	_, pageHt := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	limit := pageHt - bottom
+	html := pdf.HTMLBasicNew()
+	for j := 1; j <= 9; j++ {
+		txtStr := strings.Repeat(lorem()+" ", 1+j%4)
+		// Measure the paragraph and move it to a new page if it would not fit
+		var para func()
+		var kindStr string
+		switch j % 3 {
+		case 0:
+			kindStr = "MultiCell"
+			para = func() {
+				pdf.MultiCell(0, 5, fmt.Sprintf("%d. %s", j, txtStr), "1", "J", false)
+			}
+		case 1:
+			kindStr = "Write"
+			para = func() {
+				pdf.Write(5, fmt.Sprintf("%d. %s", j, txtStr))
+				pdf.Ln(-1)
+			}
+		default:
+			kindStr = "HTML Write"
+			para = func() {
+				html.Write(5, fmt.Sprintf("%d. <b>Measured HTML</b> <i>text</i>: %s", j, txtStr))
+				pdf.Ln(-1)
+			}
+		}
+		m := pdf.Measure(para)
+		if pdf.GetY()+m.Height > limit {
+			pdf.AddPage()
+		}
+		y := pdf.GetY()
+		para()
+		pdf.SetFont("Courier", "", 8)
+		pdf.SetTextColor(160, 0, 0)
+		pdf.Text(pdf.GetX()+2, pdf.GetY()+3, fmt.Sprintf("%d lines, %.1f mm (actual %.1f mm)",
+			m.Lines, m.Height, pdf.GetY()-y))
+		pdf.SetFont("Times", "", 12)
+		pdf.SetTextColor(0, 0, 0)
+		// The end position is measured before any page break is added
+		fmt.Printf("%d. %-10s %2d lines, height %.1f, end (%.1f, %5.1f), actual %.1f\n",
+			j, kindStr, m.Lines, m.Height, m.X, m.Y, pdf.GetY()-y)
+		pdf.Ln(6)
+	}
+	pdf.OutputAndClose(docWriter(pdf, 48))
+	// Output:
+	// 1. Write       9 lines, height 45.0, end (10.0,  55.0), actual 45.0
+	// 2. HTML Write 13 lines, height 65.0, end (10.0, 126.0), actual 65.0
+	// 3. MultiCell  17 lines, height 85.0, end (10.0, 217.0), actual 85.0
+	// 4. Write       5 lines, height 25.0, end (10.0, 248.0), actual 25.0
+	// 5. HTML Write  9 lines, height 45.0, end (10.0, 299.0), actual 45.0
+	// 6. MultiCell  13 lines, height 65.0, end (10.0, 126.0), actual 65.0
+	// 7. Write      17 lines, height 85.0, end (10.0, 217.0), actual 85.0
+	// 8. HTML Write  5 lines, height 25.0, end (10.0, 248.0), actual 25.0
+	// 9. MultiCell   9 lines, height 45.0, end (10.0, 299.0), actual 45.0
+	// Successfully generated pdf/tutorial48.pdf
+}
+
+// This example demonstrates tab stops with left, right, centered and decimal
+// alignment and dot leaders.
+func ExampleFpdf_tutorial49() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.Write(10, "Price list\n")
+	// A price list with dot leaders and prices aligned at the decimal point
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.SetTabStops([]gofpdf.TabStopType{
+		{Pos: 95, Align: "C"},
+		{Pos: 155, Align: "R"},
+		{Pos: 185, Align: "R"},
+	})
+	pdf.MultiCell(0, 6, "Article\tUnit\tPrice\tStock", "B", "L", false)
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.SetTabStops([]gofpdf.TabStopType{
+		{Pos: 95, Align: "C"},
+		{Pos: 145, Align: "D", Leader: "."},
+		{Pos: 185, Align: "R"},
+	})
+	pdf.MultiCell(0, 6, "Espresso beans, dark roast\tkg\t24.90\t120\n"+
+		"Filter papers\tbox\t3.5\t2,400\n"+
+		"Grinder, conical burr\tpiece\t189.00\t8\n"+
+		"Milk jug\tpiece\t12\t45\n"+
+		"Descaling tablets, pack of twelve\tpack\t1,049.75\t3", "", "L", false)
+	pdf.Ln(8)
+	// A form written with Write(); the stops are measured from the left
+	// margin and a comma is used as the decimal separator
+	pdf.SetTabStops([]gofpdf.TabStopType{
+		{Pos: 45, Align: "L"},
+		{Pos: 120, Align: "D", Decimal: ",", Leader: "_"},
+	})
+	pdf.Write(8, "Name:\tJane Doe\n")
+	pdf.Write(8, "Account:\tDE02 1203 0000 0000 2020 51\n")
+	pdf.Write(8, "Amount:\t")
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.Write(8, "\t1250,00 EUR\n")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.Ln(8)
+	// SplitLines breaks lines as MultiCell does
+	pdf.SetTabStops([]gofpdf.TabStopType{{Pos: 30, Align: "L", Leader: "."}})
+	txtStr := "Chapter 1\tA long title that does not fit on a single line of the narrow cell\n" +
+		"Chapter 2\tShort title"
+	lines := pdf.SplitLines([]byte(txtStr), 100)
+	pdf.MultiCell(100, 6, txtStr, "1", "L", false)
+	pdf.SetTabStops(nil)
+	pdf.Write(8, fmt.Sprintf("SplitLines() reports %d lines.", len(lines)))
+	pdf.OutputAndClose(docWriter(pdf, 49))
+	// Output:
+	// Successfully generated pdf/tutorial49.pdf
+}
+
+// This example demonstrates bold and italic styles emulated for fonts that
+// only have a regular style, and small capitals.
+func ExampleFpdf_tutorial50() {
+	pdf := gofpdf.New("P", "mm", "A4", cnFontDir)
+	pdf.AddUTF8Font("DejaVu", "", "DejaVuSans.ttf")
+	pdf.AddFont("Calligrapher", "", "calligra.json")
+	pdf.SetSyntheticStyles(true)
+	pdf.AddPage()
+	for _, familyStr := range []string{"DejaVu", "Calligrapher"} {
+		for _, styleStr := range []string{"", "B", "I", "BI", "C", "BC"} {
+			pdf.SetFont(familyStr, styleStr, 18)
+			txtStr := fmt.Sprintf("%s %q: Quick brown fox", familyStr, styleStr)
+			// The cell border shows the width reported by GetStringWidth()
+			pdf.CellFormat(pdf.GetStringWidth(txtStr)+2*pdf.GetCellMargin(), 10, txtStr, "1", 1, "L", false, 0, "")
+		}
+		pdf.Ln(4)
+	}
+	// Synthetic styles mixed with regular text in flowing text
+	pdf.SetFont("DejaVu", "", 12)
+	pdf.Write(6, "Text in the regular style is followed by ")
+	pdf.SetFont("", "B", 0)
+	pdf.Write(6, "emboldened text")
+	pdf.SetFont("", "", 0)
+	pdf.Write(6, ", ")
+	pdf.SetFont("", "I", 0)
+	pdf.Write(6, "slanted text")
+	pdf.SetFont("", "", 0)
+	pdf.Write(6, " and ")
+	pdf.SetFont("", "C", 0)
+	pdf.Write(6, "Small Capitals")
+	pdf.SetFont("", "", 0)
+	pdf.Write(6, ", all with the same embedded font.")
+	pdf.Ln(10)
+	pdf.SetFont("DejaVu", "B", 12)
+	pdf.SetTextColor(160, 30, 30)
+	pdf.MultiCell(100, 6, "Emboldened text is wider than regular text, and MultiCell() "+
+		"takes the extra width into account when it breaks lines.", "1", "J", false)
+	pdf.OutputAndClose(docWriter(pdf, 50))
+	// Output:
+	// Successfully generated pdf/tutorial50.pdf
+}
+
+// This example demonstrates that the ink bounds of small capitals are those
+// of the capital letters at the reduced size.
+func ExampleFpdf_GetStringBounds() {
+	pdf := gofpdf.New("P", "mm", "A4", cnFontDir)
+	pdf.AddUTF8Font("DejaVu", "", "DejaVuSans.ttf")
+	pdf.AddPage()
+	for _, styleStr := range []string{"", "C"} {
+		pdf.SetFont("DejaVu", styleStr, 20)
+		for _, s := range []string{"x", "X"} {
+			_, _, w, h := pdf.GetStringBounds(s)
+			fmt.Printf("%-2s %s: advance %.2f, ink %.2f x %.2f\n", styleStr, s, pdf.GetStringWidth(s), w, h)
+		}
+	}
+	pdf.Close()
+	// Output:
+	//    x: advance 4.18, ink 3.74 x 3.86
+	//    X: advance 4.83, ink 4.40 x 5.14
+	// C  x: advance 3.63, ink 3.30 x 3.86
+	// C  X: advance 4.83, ink 4.40 x 5.14
+}
+
+// This example demonstrates text placed along paths made of lines, arcs and
+// Bézier curves, and text along a path in an SVG image.
+func ExampleFpdf_tutorial51() {
+	type seg = gofpdf.SVGBasicSegmentType
+	pdf := gofpdf.New("P", "mm", "A4", cnFontDir)
+	pdf.AddUTF8Font("DejaVu", "", "DejaVuSans.ttf")
+	pdf.AddPage()
+	// A stamp: text over the top of a circle, drawn clockwise, and below
+	// the bottom, drawn counter-clockwise so that the text is upright
+	cx, cy, r := 60.0, 60.0, 35.0
+	pdf.SetDrawColor(160, 30, 30)
+	pdf.SetTextColor(160, 30, 30)
+	pdf.SetLineWidth(1)
+	pdf.Circle(cx, cy, r+8, "D")
+	pdf.Circle(cx, cy, r-6, "D")
+	pdf.SetFont("Helvetica", "B", 16)
+	top := []seg{{Cmd: 'M', Arg: [6]float64{cx - r, cy}},
+		{Cmd: 'A', Arg: [6]float64{r, r, 0, 1, cx + r, cy}}}
+	pdf.TextAlongPath(top, "CERTIFIED ORIGINAL", math.Pi*r/2, "C", 0.8)
+	bottom := []seg{{Cmd: 'M', Arg: [6]float64{cx - r - 4.5, cy}},
+		{Cmd: 'A', Arg: [6]float64{r + 4.5, r + 4.5, 0, 0, cx + r + 4.5, cy}}}
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.TextAlongPath(bottom, "* 2024 *", math.Pi*(r+4.5)/2, "C", 0.5)
+	pdf.SetFont("Times", "B", 30)
+	pdf.SetXY(cx-20, cy-6)
+	pdf.CellFormat(40, 12, "GRADE A", "", 0, "C", false, 0, "")
+	// A wave of Bézier curves; the path is drawn for reference
+	pdf.SetDrawColor(180, 180, 180)
+	pdf.SetLineWidth(0.2)
+	pdf.SetTextColor(20, 60, 140)
+	wave := []seg{{Cmd: 'M', Arg: [6]float64{110, 60}},
+		{Cmd: 'C', Arg: [6]float64{125, 30, 145, 30, 160, 60}},
+		{Cmd: 'C', Arg: [6]float64{175, 90, 190, 90, 200, 60}}}
+	pdf.CurveBezierCubic(110, 60, 125, 30, 145, 30, 160, 60, "D")
+	pdf.CurveBezierCubic(160, 60, 175, 90, 190, 90, 200, 60, "D")
+	pdf.SetFont("DejaVu", "", 14)
+	pdf.TextAlongPath(wave, "Text follows the curve — ünïcödé too", 0, "L", 0)
+	// Lines with a corner, with the text centered on the corner
+	pdf.SetFont("Helvetica", "I", 12)
+	pdf.SetTextColor(0, 0, 0)
+	zigzag := []seg{{Cmd: 'M', Arg: [6]float64{110, 130}},
+		{Cmd: 'L', Arg: [6]float64{150, 110}},
+		{Cmd: 'L', Arg: [6]float64{200, 130}}}
+	pdf.Polygon([]gofpdf.PointType{{X: 110, Y: 130}, {X: 150, Y: 110}, {X: 200, Y: 130}}, "D")
+	pdf.TextAlongPath(zigzag, "up the hill and down again", 44.7, "C", 0)
+	// An SVG image with a textPath element that refers to a path in defs
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink">
+<clipPath><rect x="0" y="0" width="180" height="80"/></clipPath>
+<defs><path id="arch" d="M 10 70 a 80 50 0 0 1 160 0"/></defs>
+<path d="M 10 70 a 80 50 0 0 1 160 0"/>
+<text style="font-size:9;fill:#006633" text-anchor="middle"><textPath xlink:href="#arch" startOffset="50%">Text on an SVG path</textPath></text>
+</svg>`
+	sig, err := gofpdf.SVGBasicParse([]byte(svg))
+	if err == nil {
+		pdf.SetFont("Helvetica", "", 12)
+		pdf.SetXY(0, 0)
+		pdf.TransformBegin()
+		pdf.TransformTranslate(15, 160)
+		pdf.SVGBasicWrite(&sig, 1)
+		pdf.SVGWriteTexts(&sig, 1)
+		pdf.TransformEnd()
+	} else {
+		pdf.SetError(err)
+	}
+	pdf.OutputAndClose(docWriter(pdf, 51))
+	// Output:
+	// Successfully generated pdf/tutorial51.pdf
+}
+
+// This example demonstrates text that flows through columns, columns of
+// different widths and a section that ends with balanced columns.
+func ExampleFpdf_tutorial52() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(0, 8, "The Gofpdf Gazette", "B", 1, "C", false, 0, "")
+		pdf.Ln(4)
+	})
+	pdf.AddPage()
+	pdf.SetFont("Times", "B", 24)
+	pdf.CellFormat(0, 12, "News in three columns", "", 1, "C", false, 0, "")
+	pdf.Ln(2)
+	article := func(j int) {
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.MultiCell(0, 6, fmt.Sprintf("Article %d", j), "", "L", false)
+		pdf.SetFont("Times", "", 10)
+		pdf.MultiCell(0, 4.5, strings.Repeat(lorem()+" ", 1+j%2), "", "J", false)
+		pdf.Ln(3)
+	}
+	// Text flows from column to column and then to the next page
+	pdf.SetColumns(3, 6)
+	for j := 1; j <= 9; j++ {
+		article(j)
+	}
+	pdf.EndColumns()
+	pdf.Ln(4)
+	pdf.SetFont("Times", "B", 18)
+	pdf.CellFormat(0, 10, "A balanced section with columns of different widths", "T", 1, "C", false, 0, "")
+	pdf.Ln(2)
+	pdf.SetColumnWidths([]float64{50, 80, 50}, 5)
+	pdf.BalanceColumns(func() {
+		for j := 1; j <= 2; j++ {
+			article(j)
+		}
+		pdf.SetFont("Times", "I", 10)
+		pdf.Write(4.5, lorem())
+	})
+	pdf.EndColumns()
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(0, 8, "The columns above end at the same height.", "T", 1, "C", false, 0, "")
+	pdf.OutputAndClose(docWriter(pdf, 52))
+	// Output:
+	// Successfully generated pdf/tutorial52.pdf
+}
+
+// This example demonstrates text that wraps around images and shapes by means
+// of exclusion regions.
+func ExampleFpdf_tutorial53() {
+	const gap = 3
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, "Text wrapped around images and shapes", "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+	// A photo floated to the right of a paragraph
+	fileStr := imageFile("golang-gopher.png")
+	info := pdf.RegisterImage(fileStr, "")
+	x, y, wd := 140.0, pdf.GetY(), 60.0
+	ht := wd * info.Height() / info.Width()
+	pdf.Image(fileStr, x, y, wd, ht, false, "", 0, "")
+	pdf.AddExclusionRect(x-gap, y-gap, wd+2*gap, ht+2*gap)
+	pdf.SetFont("Times", "", 11)
+	pdf.MultiCell(0, 5, lorem(), "", "J", false)
+	pdf.Ln(5)
+	// A logo floated to the left of text printed with Write()
+	fileStr = imageFile("logo.png")
+	info = pdf.RegisterImage(fileStr, "")
+	x, y, wd = 10, pdf.GetY(), 40
+	ht = wd * info.Height() / info.Width()
+	pdf.Image(fileStr, x, y, wd, ht, false, "", 0, "")
+	pdf.AddExclusionRect(x, y-gap, wd+gap, ht+2*gap)
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.Write(5, lorem())
+	pdf.Ln(10)
+	// A diamond set off-center within the text; each line is placed in the
+	// wider part beside it
+	y = pdf.GetY()
+	diamond := []gofpdf.PointType{{X: 80, Y: y}, {X: 110, Y: y + 25}, {X: 80, Y: y + 50}, {X: 50, Y: y + 25}}
+	pdf.SetFillColor(200, 220, 255)
+	pdf.Polygon(diamond, "F")
+	for j := range diamond {
+		diamond[j].X += (diamond[j].X - 80) * 0.2
+		diamond[j].Y += (diamond[j].Y - y - 25) * 0.1
+	}
+	pdf.AddExclusionPolygon(diamond)
+	pdf.SetFont("Times", "", 11)
+	pdf.MultiCell(0, 5, lorem()+" "+lorem(), "", "L", false)
+	pdf.ClearExclusions()
+	pdf.Ln(5)
+	pdf.SetFont("Helvetica", "I", 10)
+	pdf.MultiCell(0, 5, "After ClearExclusions(), text spans the page again.", "", "C", false)
+	pdf.OutputAndClose(docWriter(pdf, 53))
+	// Output:
+	// Successfully generated pdf/tutorial53.pdf
+}
+
+// This example demonstrates the hyphenation of words with TeX patterns.
+func ExampleHyphenatorFromFile() {
+	hyphenate, err := gofpdf.HyphenatorFromFile(hyphenFile("hyph-en-us.tex"), 5, 2, 3)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, word := range []string{"hyphenation", "manuscript", "(typesetting)", "table"} {
+		runes := []rune(word)
+		var parts []string
+		pos := 0
+		for _, k := range hyphenate(runes) {
+			parts = append(parts, string(runes[pos:k]))
+			pos = k
+		}
+		parts = append(parts, string(runes[pos:]))
+		fmt.Println(strings.Join(parts, "-"))
+	}
+	// Output:
+	// hy-phen-a-tion
+	// man-u-script
+	// (type-set-ting)
+	// ta-ble
+}
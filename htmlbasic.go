@@ -118,14 +118,14 @@ func (f *Fpdf) HTMLBasicNew() (html HTMLBasicType) {
 // Write prints text from the current position using the currently selected
 // font. See HTMLBasicNew() to create a receiver that is associated with the
 // PDF document instance. The text can be encoded with a basic subset of HTML
-// that includes hyperlinks and tags for italic (I), bold (B) and underscore
-// (U) attributes. When the right margin is reached a line break occurs and
-// text continues from the left margin. Upon method exit, the current position
-// is left at the end of the text.
+// that includes hyperlinks and tags for italic (I), bold (B), underscore (U)
+// and strikethrough (S, DEL and STRIKE) attributes. When the right margin is
+// reached a line break occurs and text continues from the left margin. Upon
+// method exit, the current position is left at the end of the text.
 //
 // lineHt indicates the line height in the unit of measure specified in New().
 func (html *HTMLBasicType) Write(lineHt float64, htmlStr string) {
-	var boldLvl, italicLvl, underscoreLvl, linkBold, linkItalic, linkUnderscore int
+	var boldLvl, italicLvl, underscoreLvl, strikeLvl, linkBold, linkItalic, linkUnderscore int
 	var textR, textG, textB = html.pdf.GetTextColor()
 	var hrefStr string
 	if html.Link.Bold {
@@ -137,7 +137,7 @@ func (html *HTMLBasicType) Write(lineHt float64, htmlStr string) {
 	if html.Link.Underscore {
 		linkUnderscore = 1
 	}
-	setStyle := func(boldAdj, italicAdj, underscoreAdj int) {
+	setStyle := func(boldAdj, italicAdj, underscoreAdj, strikeAdj int) {
 		styleStr := ""
 		boldLvl += boldAdj
 		if boldLvl > 0 {
@@ -151,14 +151,18 @@ func (html *HTMLBasicType) Write(lineHt float64, htmlStr string) {
 		if underscoreLvl > 0 {
 			styleStr += "U"
 		}
+		strikeLvl += strikeAdj
+		if strikeLvl > 0 {
+			styleStr += "S"
+		}
 		html.pdf.SetFont("", styleStr, 0)
 	}
 	putLink := func(urlStr, txtStr string) {
 		// Put a hyperlink
 		html.pdf.SetTextColor(html.Link.ClrR, html.Link.ClrG, html.Link.ClrB)
-		setStyle(linkBold, linkItalic, linkUnderscore)
+		setStyle(linkBold, linkItalic, linkUnderscore, 0)
 		html.pdf.WriteLinkString(lineHt, txtStr, urlStr)
-		setStyle(-linkBold, -linkItalic, -linkUnderscore)
+		setStyle(-linkBold, -linkItalic, -linkUnderscore, 0)
 		html.pdf.SetTextColor(textR, textG, textB)
 	}
 	list := HTMLBasicTokenize(htmlStr)
@@ -175,11 +179,13 @@ func (html *HTMLBasicType) Write(lineHt float64, htmlStr string) {
 		case 'O':
 			switch el.Str {
 			case "b":
-				setStyle(1, 0, 0)
+				setStyle(1, 0, 0, 0)
 			case "i":
-				setStyle(0, 1, 0)
+				setStyle(0, 1, 0, 0)
 			case "u":
-				setStyle(0, 0, 1)
+				setStyle(0, 0, 1, 0)
+			case "s", "del", "strike":
+				setStyle(0, 0, 0, 1)
 			case "br":
 				html.pdf.Ln(lineHt)
 			case "a":
@@ -191,11 +197,13 @@ func (html *HTMLBasicType) Write(lineHt float64, htmlStr string) {
 		case 'C':
 			switch el.Str {
 			case "b":
-				setStyle(-1, 0, 0)
+				setStyle(-1, 0, 0, 0)
 			case "i":
-				setStyle(0, -1, 0)
+				setStyle(0, -1, 0, 0)
 			case "u":
-				setStyle(0, 0, -1)
+				setStyle(0, 0, -1, 0)
+			case "s", "del", "strike":
+				setStyle(0, 0, 0, -1)
 
 			}
 		}
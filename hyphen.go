@@ -0,0 +1,231 @@
+package gofpdf
+
+// Hyphenation with the pattern algorithm of Franklin Liang, as used by TeX.
+// Patterns and exceptions are read from files in the TeX format.
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+type hyphenPatternsType struct {
+	patterns   map[string][]int // values between the letters of each pattern, keyed by letters
+	exceptions map[string][]int // hyphen positions of exceptional words
+	maxLen     int              // length in runes of the longest pattern
+	minWord    int
+	leftMin    int
+	rightMin   int
+}
+
+// Hyphenator returns a function that finds the positions at which a word can
+// be hyphenated, using the Liang algorithm that is employed by TeX. The
+// returned function can be passed to SetHyphenFunc().
+//
+// r is a reader for a TeX hyphenation pattern file in UTF-8 encoding. Patterns
+// are read from the \patterns{} group and exceptional words, in which the
+// permitted hyphens are given explicitly, from the \hyphenation{} group.
+// Comments begin with "%". Pattern files for American English
+// (hyph-en-us.tex) and German (hyph-de-1996.tex) are packaged with the gofpdf
+// library in the hyphen directory.
+//
+// minWord is the length, in letters, of the shortest word that is hyphenated.
+// leftMin and rightMin are the minimum number of letters that remain before
+// and after a hyphen. Reasonable values for English are 5, 2 and 3.
+//
+// The returned function accepts a word and returns, in increasing order, the
+// rune indexes before which a hyphen can be inserted. Punctuation that
+// precedes or follows the word is ignored. Words that contain other
+// characters that are not letters, such as digits or an existing hyphen, are
+// not hyphenated.
+//
+// An error occurs if the pattern file is malformed. In this case, the returned
+// function is valid but does not hyphenate any word.
+func Hyphenator(r io.Reader, minWord, leftMin, rightMin int) (f func(word []rune) []int, err error) {
+	hp := hyphenPatternsType{
+		patterns:   make(map[string][]int),
+		exceptions: make(map[string][]int),
+		minWord:    minWord,
+		leftMin:    leftMin,
+		rightMin:   rightMin,
+	}
+	err = hp.parse(r)
+	if err == nil {
+		f = hp.hyphenate
+	} else {
+		f = func(word []rune) []int {
+			return nil
+		}
+	}
+	return
+}
+
+// HyphenatorFromFile returns a function that finds the positions at which a
+// word can be hyphenated. See Hyphenator() for more details.
+//
+// fileStr identifies a TeX hyphenation pattern file.
+//
+// If an error occurs reading the file, the returned function is valid but
+// does not hyphenate any word.
+func HyphenatorFromFile(fileStr string, minWord, leftMin, rightMin int) (f func(word []rune) []int, err error) {
+	var fl *os.File
+	fl, err = os.Open(fileStr)
+	if err == nil {
+		f, err = Hyphenator(fl, minWord, leftMin, rightMin)
+		fl.Close()
+	} else {
+		f = func(word []rune) []int {
+			return nil
+		}
+	}
+	return
+}
+
+// parse reads the patterns and exceptions of a TeX hyphenation file
+func (hp *hyphenPatternsType) parse(r io.Reader) (err error) {
+	var group string
+	lineNum := 0
+	sc := bufio.NewScanner(r)
+	for sc.Scan() && err == nil {
+		lineNum++
+		lineStr := sc.Text()
+		if pos := strings.IndexByte(lineStr, '%'); pos >= 0 {
+			lineStr = lineStr[:pos]
+		}
+		for _, tok := range strings.Fields(lineStr) {
+			if group == "" {
+				switch {
+				case strings.HasPrefix(tok, `\patterns{`):
+					group = "patterns"
+					tok = tok[len(`\patterns{`):]
+				case strings.HasPrefix(tok, `\hyphenation{`):
+					group = "hyphenation"
+					tok = tok[len(`\hyphenation{`):]
+				default:
+					// Other TeX commands are not relevant
+					continue
+				}
+			}
+			closing := strings.HasSuffix(tok, "}")
+			if closing {
+				tok = tok[:len(tok)-1]
+			}
+			err = hp.add(group, tok)
+			if err != nil {
+				err = fmt.Errorf("line %d: %s", lineNum, err)
+				break
+			}
+			if closing {
+				group = ""
+			}
+		}
+	}
+	if err == nil {
+		err = sc.Err()
+	}
+	if err == nil && group != "" {
+		err = fmt.Errorf("unterminated \\%s group", group)
+	}
+	return
+}
+
+// add records a pattern such as "hy3ph" or an exceptional word such as
+// "as-so-ciate"
+func (hp *hyphenPatternsType) add(group, tok string) error {
+	if tok == "" {
+		return nil
+	}
+	var letters []rune
+	if group == "patterns" {
+		values := []int{0}
+		for _, r := range tok {
+			if r >= '0' && r <= '9' {
+				values[len(values)-1] = int(r - '0')
+			} else {
+				letters = append(letters, unicode.ToLower(r))
+				values = append(values, 0)
+			}
+		}
+		if len(letters) == 0 {
+			return fmt.Errorf("invalid pattern %s", tok)
+		}
+		hp.patterns[string(letters)] = values
+		if len(letters) > hp.maxLen {
+			hp.maxLen = len(letters)
+		}
+	} else {
+		var positions []int
+		for _, r := range tok {
+			if r == '-' {
+				positions = append(positions, len(letters))
+			} else {
+				letters = append(letters, unicode.ToLower(r))
+			}
+		}
+		hp.exceptions[string(letters)] = positions
+	}
+	return nil
+}
+
+// hyphenate returns the rune indexes of word before which a hyphen can be
+// inserted
+func (hp *hyphenPatternsType) hyphenate(word []rune) (positions []int) {
+	// Ignore surrounding punctuation
+	start, end := 0, len(word)
+	for start < end && !unicode.IsLetter(word[start]) {
+		start++
+	}
+	for end > start && !unicode.IsLetter(word[end-1]) {
+		end--
+	}
+	n := end - start
+	if n < hp.minWord || n < hp.leftMin+hp.rightMin {
+		return
+	}
+	letters := make([]rune, n)
+	for j, r := range word[start:end] {
+		if !unicode.IsLetter(r) {
+			return
+		}
+		letters[j] = unicode.ToLower(r)
+	}
+	allowed := func(pos int) bool {
+		return pos >= hp.leftMin && pos <= n-hp.rightMin
+	}
+	if list, ok := hp.exceptions[string(letters)]; ok {
+		for _, pos := range list {
+			if allowed(pos) {
+				positions = append(positions, start+pos)
+			}
+		}
+		return
+	}
+	// Values between the letters of ".word.", where values[k] precedes the
+	// k-th character
+	str := make([]rune, 0, n+2)
+	str = append(str, '.')
+	str = append(str, letters...)
+	str = append(str, '.')
+	values := make([]int, len(str)+1)
+	for j := range str {
+		for k := j + 1; k <= len(str) && k-j <= hp.maxLen; k++ {
+			if pattern, ok := hp.patterns[string(str[j:k])]; ok {
+				for m, v := range pattern {
+					if v > values[j+m] {
+						values[j+m] = v
+					}
+				}
+			}
+		}
+	}
+	for pos := 1; pos < n; pos++ {
+		// A hyphen before letter pos precedes character pos+1 of ".word."
+		if values[pos+1]%2 == 1 && allowed(pos) {
+			positions = append(positions, start+pos)
+		}
+	}
+	return
+}
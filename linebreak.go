@@ -0,0 +1,294 @@
+package gofpdf
+
+// Optimal line breaking with the total-fit algorithm of Donald Knuth and
+// Michael Plass. A paragraph is modeled as a sequence of boxes (words and
+// word fragments), glue (spaces that can stretch and shrink) and penalties
+// (possible hyphenation points). Among all ways of breaking the paragraph
+// into lines, the one with the fewest total demerits is chosen, so that the
+// spacing of all lines, rather than just the current one, is taken into
+// account.
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	kpBox = iota
+	kpGlue
+	kpPenalty
+)
+
+const (
+	kpInfinity        = 1e4  // penalty that prohibits (or, negated, forces) a break
+	kpFillStretch     = 1e10 // stretchability of the glue that fills the last line
+	kpLinePenalty     = 10   // demerits added to each line
+	kpHyphenPenalty   = 50   // penalty for breaking at a hyphenation point
+	kpFlaggedDemerits = 3000 // demerits for consecutive hyphenated lines
+	kpFitnessDemerits = 3000 // demerits for adjacent lines of very different tightness
+)
+
+type kpItemType struct {
+	kind    int
+	pos     int     // byte position of the item in the text
+	end     int     // byte position that follows the item
+	width   float64 // in thousandths of the font size
+	stretch float64
+	shrink  float64
+	penalty float64
+	flagged bool // penalty at which a hyphen is inserted
+}
+
+type kpNodeType struct {
+	item     int // index of the break item, -1 for the start of the paragraph
+	line     int
+	fitness  int
+	width    float64 // totals of the items that precede the line that follows the break
+	stretch  float64
+	shrink   float64
+	demerits float64
+	prev     *kpNodeType
+}
+
+// kpItems returns the boxes, glue and penalties of the paragraph s, which
+// contains no line feeds. adv holds the advance of each byte position of s.
+// hyphenWd is the width of a hyphen.
+func (f *Fpdf) kpItems(s string, adv []float64, hyphenWd float64) (items []kpItemType) {
+	for j := 0; j < len(s); {
+		if s[j] == ' ' {
+			// Spaces do not shrink so that no line is wider than the cell,
+			// as with the first-fit algorithm
+			items = append(items, kpItemType{kind: kpGlue, pos: j, end: j + 1, width: adv[j],
+				stretch: adv[j] / 2})
+			j++
+			continue
+		}
+		// Word, which ends at a space or at the end of the paragraph
+		end := j
+		for end < len(s) && s[end] != ' ' {
+			end++
+		}
+		// Byte positions at which the word can be broken, and those of them
+		// that follow explicit hyphens
+		var breaks []int
+		explicitHyphens := make(map[int]bool)
+		var word []rune
+		var offsets []int
+		for k := j; k < end; {
+			r, size := f.nextChar(s, k)
+			word = append(word, r)
+			offsets = append(offsets, k)
+			k += size
+			if r == '-' && k < end {
+				breaks = append(breaks, k)
+				explicitHyphens[k] = true
+			}
+		}
+		if f.hyphenFnc != nil {
+			for _, k := range f.hyphenFnc(word) {
+				if k > 0 && k < len(word) {
+					breaks = append(breaks, offsets[k])
+				}
+			}
+		}
+		sort.Ints(breaks)
+		start := j
+		for _, b := range breaks {
+			if b <= start {
+				continue
+			}
+			explicit := explicitHyphens[b]
+			items = append(items, kpBoxItem(start, b, adv))
+			if explicit {
+				items = append(items, kpItemType{kind: kpPenalty, pos: b, end: b, penalty: kpHyphenPenalty, flagged: true})
+			} else {
+				items = append(items, kpItemType{kind: kpPenalty, pos: b, end: b, width: hyphenWd,
+					penalty: kpHyphenPenalty, flagged: true})
+			}
+			start = b
+		}
+		items = append(items, kpBoxItem(start, end, adv))
+		j = end
+	}
+	// The last line is filled with glue and ends with a forced break
+	items = append(items,
+		kpItemType{kind: kpPenalty, pos: len(s), end: len(s), penalty: kpInfinity},
+		kpItemType{kind: kpGlue, pos: len(s), end: len(s), stretch: kpFillStretch},
+		kpItemType{kind: kpPenalty, pos: len(s), end: len(s), penalty: -kpInfinity, flagged: true})
+	return
+}
+
+func kpBoxItem(pos, end int, adv []float64) kpItemType {
+	item := kpItemType{kind: kpBox, pos: pos, end: end}
+	for _, a := range adv[pos:end] {
+		item.width += a
+	}
+	return item
+}
+
+// kpFitness returns the fitness class of a line with adjustment ratio r
+func kpFitness(r float64) int {
+	switch {
+	case r < -0.5:
+		return 0
+	case r <= 0.5:
+		return 1
+	case r <= 1:
+		return 2
+	}
+	return 3
+}
+
+// kpBreaks returns the indexes of the items at which the paragraph is broken
+// into lines of width lineWd, or nil if the paragraph cannot be broken with
+// adjustment ratios up to tolerance
+func kpBreaks(items []kpItemType, lineWd, tolerance float64) (breaks []int) {
+	active := []*kpNodeType{{item: -1}}
+	var sumW, sumY, sumZ float64
+	for b, item := range items {
+		switch item.kind {
+		case kpBox:
+			sumW += item.width
+			continue
+		case kpGlue:
+			if b == 0 || items[b-1].kind != kpBox {
+				sumW += item.width
+				sumY += item.stretch
+				sumZ += item.shrink
+				continue
+			}
+		case kpPenalty:
+			if item.penalty >= kpInfinity {
+				continue
+			}
+		}
+		// Feasible break at b
+		var best [4]*kpNodeType
+		next := active[:0]
+		for _, a := range active {
+			wd := sumW - a.width
+			if item.kind == kpPenalty {
+				wd += item.width
+			}
+			var r float64
+			switch {
+			case wd < lineWd:
+				if y := sumY - a.stretch; y > 0 {
+					r = (lineWd - wd) / y
+				} else {
+					r = kpInfinity
+				}
+			case wd > lineWd:
+				if z := sumZ - a.shrink; z > 0 {
+					r = (lineWd - wd) / z
+				} else {
+					r = -kpInfinity
+				}
+			}
+			forced := item.kind == kpPenalty && item.penalty <= -kpInfinity
+			if r >= -1 && !forced {
+				next = append(next, a)
+			}
+			if r < -1 || r > tolerance {
+				continue
+			}
+			badness := 100 * math.Pow(math.Abs(r), 3)
+			d := math.Pow(kpLinePenalty+badness, 2)
+			if item.kind == kpPenalty {
+				if item.penalty >= 0 {
+					d += item.penalty * item.penalty
+				} else if item.penalty > -kpInfinity {
+					d -= item.penalty * item.penalty
+				}
+				if item.flagged && a.item >= 0 && items[a.item].kind == kpPenalty && items[a.item].flagged {
+					d += kpFlaggedDemerits
+				}
+			}
+			fit := kpFitness(r)
+			if a.item >= 0 && (fit-a.fitness > 1 || a.fitness-fit > 1) {
+				d += kpFitnessDemerits
+			}
+			d += a.demerits
+			if best[fit] == nil || d < best[fit].demerits {
+				best[fit] = &kpNodeType{item: b, line: a.line + 1, fitness: fit, demerits: d, prev: a}
+			}
+		}
+		active = next
+		// Totals after the break exclude the glue and penalties that are
+		// discarded at the start of the next line
+		w, y, z := sumW, sumY, sumZ
+		for k := b; k < len(items); k++ {
+			if items[k].kind == kpBox || k > b && items[k].kind == kpPenalty && items[k].penalty <= -kpInfinity {
+				break
+			}
+			if items[k].kind == kpGlue {
+				w += items[k].width
+				y += items[k].stretch
+				z += items[k].shrink
+			}
+		}
+		for _, n := range best {
+			if n != nil {
+				n.width, n.stretch, n.shrink = w, y, z
+				active = append(active, n)
+			}
+		}
+		if len(active) == 0 {
+			return nil
+		}
+		if item.kind == kpGlue {
+			sumW += item.width
+			sumY += item.stretch
+			sumZ += item.shrink
+		}
+	}
+	var last *kpNodeType
+	for _, a := range active {
+		if a.item == len(items)-1 && (last == nil || a.demerits < last.demerits) {
+			last = a
+		}
+	}
+	for n := last; n != nil && n.item >= 0; n = n.prev {
+		breaks = append([]int{n.item}, breaks...)
+	}
+	return
+}
+
+// splitOptimal breaks the paragraph s, which contains no line feeds, into
+// lines no wider than wmax (in thousandths of the font size) with the
+// total-fit algorithm. Lines that end at a hyphenation point include a
+// hyphen. The greedy algorithm of SplitLines() is used if no acceptable set of
+// breaks exists, for example if a word is wider than a line.
+func (f *Fpdf) splitOptimal(s []byte, wmax float64) (lines [][]byte) {
+	str := string(s)
+	if len(str) == 0 {
+		return [][]byte{s}
+	}
+	adv := f.advances(str)
+	items := f.kpItems(str, adv, f.advances("-")[0])
+	var breaks []int
+	for _, tolerance := range []float64{1, 2, 10} {
+		breaks = kpBreaks(items, wmax, tolerance)
+		if breaks != nil {
+			break
+		}
+	}
+	if breaks == nil {
+		return f.splitGreedy(s, wmax)
+	}
+	start := 0
+	for _, b := range breaks {
+		item := items[b]
+		line := s[start:item.pos]
+		if item.kind == kpPenalty && item.width > 0 {
+			line = append(line[:len(line):len(line)], '-')
+		}
+		lines = append(lines, line)
+		// The next line starts at the following box
+		start = item.end
+		for k := b + 1; k < len(items) && items[k].kind != kpBox; k++ {
+			start = items[k].end
+		}
+	}
+	return
+}
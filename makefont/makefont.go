@@ -15,6 +15,7 @@ func showHelp() {
 	errPrintf("Usage: %s [options] font_file [font_file...]\n", os.Args[0])
 	flag.PrintDefaults()
 	errPrintf("Example: %s --embed --enc=../font/cp1252.map --dst=../font calligra.ttf /opt/font/symbol.pfb\n", os.Args[0])
+	errPrintf("Example: %s --embed --enc=../font/cp1252.map --pkg=fonts --dst=fonts calligra.ttf\n", os.Args[0])
 }
 
 func tutorialSummary(f *gofpdf.Fpdf, fileStr string) {
@@ -35,12 +36,13 @@ func tutorialSummary(f *gofpdf.Fpdf, fileStr string) {
 }
 
 func main() {
-	var dstDirStr, encodingFileStr string
+	var dstDirStr, encodingFileStr, pkgStr string
 	var err error
 	var help, embed bool
 	flag.StringVar(&dstDirStr, "dst", ".", "directory for output files (*.z, *.json)")
 	flag.StringVar(&encodingFileStr, "enc", "cp1252.map", "code page file")
 	flag.BoolVar(&embed, "embed", false, "embed font into PDF")
+	flag.StringVar(&pkgStr, "pkg", "", "package of Go source file (*.go) to generate instead of *.z and *.json")
 	flag.BoolVar(&help, "help", false, "command line usage")
 	flag.Parse()
 	if help {
@@ -49,14 +51,18 @@ func main() {
 		args := flag.Args()
 		if len(args) > 0 {
 			for _, fileStr := range args {
-				err = gofpdf.MakeFont(fileStr, encodingFileStr, dstDirStr, os.Stderr, embed)
+				if pkgStr != "" {
+					err = gofpdf.MakeFontSource(fileStr, encodingFileStr, dstDirStr, pkgStr, os.Stderr, embed)
+				} else {
+					err = gofpdf.MakeFont(fileStr, encodingFileStr, dstDirStr, os.Stderr, embed)
+				}
 				if err != nil {
 					errPrintf("%s\n", err)
 				}
 				// errPrintf("Font file [%s], Encoding file [%s], Embed [%v]\n", fileStr, encodingFileStr, embed)
 			}
 		} else {
-			errPrintf("At least one Type1 or TrueType font must be specified\n")
+			errPrintf("At least one Type1, TrueType or OpenType font must be specified\n")
 			showHelp()
 		}
 	}
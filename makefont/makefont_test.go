@@ -1,6 +1,8 @@
 package main
 
 import (
+	"io/ioutil"
+	"os"
 	"os/exec"
 	"strings"
 	"testing"
@@ -24,3 +26,31 @@ func TestMakefont(t *testing.T) {
 		t.Fatalf("Unexpected output from makefont")
 	}
 }
+
+func TestMakefontSource(t *testing.T) {
+	const expect = "Font source file successfully generated"
+	dirStr, err := ioutil.TempDir("", "makefont")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirStr)
+	err = exec.Command("go", "build").Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command("./makefont", "--dst="+dirStr, "--embed", "--pkg=fonts",
+		"--enc=../font/cp1252.map", "../font/calligra.ttf").CombinedOutput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), expect) {
+		t.Fatalf("Unexpected output from makefont")
+	}
+	buf, err := ioutil.ReadFile(dirStr + "/calligra.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(buf), "func AddCalligraFont(pdf *gofpdf.Fpdf, familyStr, styleStr string)") {
+		t.Fatalf("Registration function not found in generated source")
+	}
+}
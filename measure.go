@@ -0,0 +1,77 @@
+package gofpdf
+
+// Layout of text that is measured without producing output
+
+// measureStateType accumulates the lines of a layout being measured
+type measureStateType struct {
+	lines  int
+	rowY   float64 // top of the current line
+	bottom float64 // bottom of the lowest line
+}
+
+// cell records a cell of height h placed at vertical position y
+func (m *measureStateType) cell(y, h float64) {
+	if m.lines == 0 || y != m.rowY {
+		m.lines++
+		m.rowY = y
+	}
+	if m.lines == 1 || y+h > m.bottom {
+		m.bottom = y + h
+	}
+}
+
+// Measure calls fnc, which typically calls MultiCell(), Write(), the Write()
+// method of HTMLBasicType or other functions that place text in cells, and
+// returns the number of lines and the height that the text would occupy and
+// the current position at which it would end. Nothing is added to the
+// document: the output of fnc is discarded, links are not created and
+// automatic page breaks are not performed, so that the layout continues below
+// the bottom margin as if the page were long enough. Afterwards, the current
+// position, font, colors, line width and text state that were in effect
+// before the call are restored. This allows, for example, a block of text to
+// be moved to a new page if it does not fit on the current one.
+//
+// fnc must not add pages or call Measure(). The measured height extends from
+// the current position at the time of the call to the bottom of the last line
+// that contains a cell; line breaks that are not followed by text, such as
+// those of Ln(), are not included.
+//
+// See tutorial 48 for an example of this function.
+func (f *Fpdf) Measure(fnc func()) (m MeasureType) {
+	if f.err != nil || f.measure != nil {
+		return
+	}
+	y := f.y
+	restore := f.saveLayout()
+	accept := f.acceptPageBreak
+	f.acceptPageBreak = func() bool {
+		return false
+	}
+	state := &measureStateType{}
+	f.measure = state
+	f.silently(fnc)
+	f.measure = nil
+	f.acceptPageBreak = accept
+	m.X, m.Y = f.x, f.y
+	m.Lines = state.lines
+	if state.lines > 0 {
+		m.Height = state.bottom - y
+	}
+	restore()
+	return
+}
+
+// saveLayout returns a function that restores the current position, font,
+// colors, line width and text state to their values at the time of the call
+func (f *Fpdf) saveLayout() (restore func()) {
+	x, y, lasth, ws := f.x, f.y, f.lasth, f.ws
+	familyStr, styleStr, sizePt, sizeUnit, font := f.fontFamily, f.fontStyle, f.fontSizePt, f.fontSize, f.currentFont
+	underline, strikeout, overline, synth := f.underline, f.strikeout, f.overline, f.synth
+	clr, colorFlag, lineWidth, textState := f.color, f.colorFlag, f.lineWidth, f.textState
+	return func() {
+		f.x, f.y, f.lasth, f.ws = x, y, lasth, ws
+		f.fontFamily, f.fontStyle, f.fontSizePt, f.fontSize, f.currentFont = familyStr, styleStr, sizePt, sizeUnit, font
+		f.underline, f.strikeout, f.overline, f.synth = underline, strikeout, overline, synth
+		f.color, f.colorFlag, f.lineWidth, f.textState = clr, colorFlag, lineWidth, textState
+	}
+}
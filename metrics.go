@@ -0,0 +1,175 @@
+package gofpdf
+
+// Font metrics and the measurement of the ink of text.
+
+// coreFontDescs holds the metrics of the core fonts, whose definitions do not
+// include a font descriptor. The values are those of the Adobe metrics files.
+var coreFontDescs = map[string]struct {
+	desc    fontDescType
+	xHeight int
+}{
+	"Courier":               {fontDescType{Ascent: 629, Descent: -157, CapHeight: 562, FontBBox: fontBoxType{-23, -250, 715, 805}}, 426},
+	"Courier-Bold":          {fontDescType{Ascent: 629, Descent: -157, CapHeight: 562, FontBBox: fontBoxType{-113, -250, 749, 801}}, 439},
+	"Courier-Oblique":       {fontDescType{Ascent: 629, Descent: -157, CapHeight: 562, ItalicAngle: -12, FontBBox: fontBoxType{-27, -250, 849, 805}}, 426},
+	"Courier-BoldOblique":   {fontDescType{Ascent: 629, Descent: -157, CapHeight: 562, ItalicAngle: -12, FontBBox: fontBoxType{-57, -250, 869, 801}}, 439},
+	"Helvetica":             {fontDescType{Ascent: 718, Descent: -207, CapHeight: 718, FontBBox: fontBoxType{-166, -225, 1000, 931}}, 523},
+	"Helvetica-Bold":        {fontDescType{Ascent: 718, Descent: -207, CapHeight: 718, FontBBox: fontBoxType{-170, -228, 1003, 962}}, 532},
+	"Helvetica-Oblique":     {fontDescType{Ascent: 718, Descent: -207, CapHeight: 718, ItalicAngle: -12, FontBBox: fontBoxType{-170, -225, 1116, 931}}, 523},
+	"Helvetica-BoldOblique": {fontDescType{Ascent: 718, Descent: -207, CapHeight: 718, ItalicAngle: -12, FontBBox: fontBoxType{-174, -228, 1114, 962}}, 532},
+	"Times-Roman":           {fontDescType{Ascent: 683, Descent: -217, CapHeight: 662, FontBBox: fontBoxType{-168, -218, 1000, 898}}, 450},
+	"Times-Bold":            {fontDescType{Ascent: 683, Descent: -217, CapHeight: 676, FontBBox: fontBoxType{-168, -218, 1000, 935}}, 461},
+	"Times-Italic":          {fontDescType{Ascent: 683, Descent: -217, CapHeight: 653, ItalicAngle: -15, FontBBox: fontBoxType{-169, -217, 1010, 883}}, 441},
+	"Times-BoldItalic":      {fontDescType{Ascent: 683, Descent: -217, CapHeight: 669, ItalicAngle: -15, FontBBox: fontBoxType{-200, -218, 996, 921}}, 462},
+	"Symbol":                {fontDescType{Ascent: 1010, Descent: -293, CapHeight: 1010, FontBBox: fontBoxType{-180, -293, 1090, 1010}}, 0},
+	"ZapfDingbats":          {fontDescType{Ascent: 820, Descent: -143, CapHeight: 820, FontBBox: fontBoxType{-1, -143, 981, 820}}, 0},
+}
+
+// Returns the font descriptor and the x-height of the current font in
+// thousandths of the font size. If the font does not specify its x-height, it
+// is estimated from the cap height.
+func (f *Fpdf) fontDesc() (desc fontDescType, xHeight int) {
+	desc = f.currentFont.Desc
+	if core, ok := coreFontDescs[f.currentFont.Name]; ok && f.currentFont.Tp == "Core" {
+		desc, xHeight = core.desc, core.xHeight
+	} else if utf := f.currentFont.utf8; utf != nil {
+		// Fonts with an older OS/2 table do not specify the heights; the
+		// outlines of "H" and "x" are measured instead
+		if utf.ttf.CapHeight == 0 {
+			if box, ok := utf.glyphBounds(utf.glyphIndex('H')); ok {
+				desc.CapHeight = box.Ymax
+			}
+		}
+		xHeight = round(utf.k * float64(utf.ttf.XHeight))
+		if xHeight == 0 {
+			if box, ok := utf.glyphBounds(utf.glyphIndex('x')); ok {
+				xHeight = box.Ymax
+			}
+		}
+	}
+	if desc.Ascent == 0 && desc.Descent == 0 {
+		desc.Ascent, desc.Descent = 800, -200
+	}
+	if desc.CapHeight == 0 {
+		desc.CapHeight = desc.Ascent
+	}
+	if xHeight == 0 {
+		xHeight = desc.CapHeight * 2 / 3
+	}
+	return
+}
+
+// Returns the ascent and the (negative) descent of the current font in
+// thousandths of the font size
+func (f *Fpdf) fontAscent() (asc, desc int) {
+	d, _ := f.fontDesc()
+	return d.Ascent, d.Descent
+}
+
+// GetFontMetrics returns the metrics of the current font at the current font
+// size, in the unit of measure specified in New(). These allow text of
+// different fonts and sizes to be aligned precisely, for example by placing
+// baselines at a common position or by centering the cap height of a label
+// within a box. The metrics of the core fonts are those of the Adobe font
+// metrics files; those of other fonts are read from the font. If a font does
+// not specify its x-height, two thirds of the cap height is assumed.
+//
+// See tutorial 42 for an example of this function.
+func (f *Fpdf) GetFontMetrics() (m FontMetricsType) {
+	if f.err != nil {
+		return
+	}
+	desc, xHeight := f.fontDesc()
+	scale := func(v int) float64 {
+		return float64(v) * f.fontSize / 1000
+	}
+	m.Size = f.fontSize
+	m.Ascent = scale(desc.Ascent)
+	m.Descent = scale(desc.Descent)
+	m.CapHeight = scale(desc.CapHeight)
+	m.XHeight = scale(xHeight)
+	m.UnderlinePosition = scale(f.currentFont.Up)
+	m.UnderlineThickness = scale(f.currentFont.Ut)
+	m.ItalicAngle = float64(desc.ItalicAngle)
+	m.Xmin = scale(desc.FontBBox.Xmin)
+	m.Ymin = scale(desc.FontBBox.Ymin)
+	m.Xmax = scale(desc.FontBBox.Xmax)
+	m.Ymax = scale(desc.FontBBox.Ymax)
+	return
+}
+
+// GetStringBounds returns the ink bounding box of s, the smallest rectangle
+// that encloses the glyphs of s when they are written with the current font.
+// The rectangle is relative to the origin of the text, the left end of the
+// baseline, as used by Text(); like other page coordinates, y increases
+// downward, so the top of the box is normally negative. The values are in the
+// unit of measure specified in New(), so that the box can be drawn with
+// Rect(x+bx, y+by, bw, bh) after Text(x, y, s). Kerning, character and word
+// spacing, horizontal scaling, text rise and small capitals are taken into
+// account.
+//
+// The outlines of Unicode fonts with TrueType outlines (see AddUTF8Font())
+// are measured exactly. For other fonts, whose glyph outlines are not
+// available, each glyph is assumed to extend over its advance width from the
+// descent to the ascent of the font. All values are zero if s has no ink.
+//
+// See tutorial 42 for an example of this function.
+func (f *Fpdf) GetStringBounds(s string) (x, y, w, h float64) {
+	if f.err != nil {
+		return
+	}
+	asc, desc := f.fontAscent()
+	scale := f.textState.scale / 100
+	pen := 0.0
+	found := false
+	var x0, y0, x1, y1 float64
+	for _, g := range f.glyphs(s) {
+		// Small capitals are drawn at a reduced size
+		size := 1.0
+		if g.caps {
+			size = smallCapsScale
+		}
+		var box fontBoxType
+		ok := false
+		if utf := f.glyphFont(g.font).utf8; utf != nil {
+			box, ok = utf.glyphBounds(g.code)
+			if !ok && !utf.ttf.CFF {
+				// Empty glyph such as a space
+				pen += f.glyphAdvance(g)
+				continue
+			}
+		}
+		var gx0, gx1, gy0, gy1 float64
+		if ok {
+			gx0, gx1 = pen+float64(box.Xmin)*size*scale, pen+float64(box.Xmax)*size*scale
+			gy0, gy1 = float64(box.Ymin)*size, float64(box.Ymax)*size
+		} else {
+			if len(g.text) == 1 && g.text[0] == ' ' {
+				pen += f.glyphAdvance(g)
+				continue
+			}
+			// The advance width of a small capital is already reduced
+			gx0, gx1 = pen, pen+float64(g.wd)*scale
+			gy0, gy1 = float64(desc)*size, float64(asc)*size
+		}
+		if !found || gx0 < x0 {
+			x0 = gx0
+		}
+		if !found || gx1 > x1 {
+			x1 = gx1
+		}
+		if !found || gy0 < y0 {
+			y0 = gy0
+		}
+		if !found || gy1 > y1 {
+			y1 = gy1
+		}
+		found = true
+		pen += f.glyphAdvance(g)
+	}
+	if !found {
+		return
+	}
+	k := f.fontSize / 1000
+	rise := f.textState.rise
+	return x0 * k, -y1*k - rise, (x1 - x0) * k, (y1 - y0) * k
+}
@@ -0,0 +1,263 @@
+package gofpdf
+
+// Text placed along a path of lines, arcs and curves
+
+import (
+	"math"
+)
+
+// pathPieceType is a cubic Bézier curve of a path, or a straight line
+// expressed as one, with the part of it that is covered by a step of the
+// flattened path
+type pathPieceType struct {
+	p      [8]float64 // start point, control points and end point
+	t0, t1 float64    // parameters of the start and end of the step
+	s      float64    // distance along the path to the start of the step
+	length float64    // length of the step
+}
+
+// point returns the point of the curve at parameter t and the direction of
+// the curve there
+func (pc pathPieceType) point(t float64) (x, y, dx, dy float64) {
+	p := pc.p
+	u := 1 - t
+	x = u*u*u*p[0] + 3*u*u*t*p[2] + 3*u*t*t*p[4] + t*t*t*p[6]
+	y = u*u*u*p[1] + 3*u*u*t*p[3] + 3*u*t*t*p[5] + t*t*t*p[7]
+	dx = 3*u*u*(p[2]-p[0]) + 6*u*t*(p[4]-p[2]) + 3*t*t*(p[6]-p[4])
+	dy = 3*u*u*(p[3]-p[1]) + 6*u*t*(p[5]-p[3]) + 3*t*t*(p[7]-p[5])
+	return
+}
+
+// flattenPath divides the drawn segments of path into short steps and
+// returns them with the total length of the path. Moves do not contribute to
+// the length.
+func flattenPath(path []SVGBasicSegmentType) (pieces []pathPieceType, length float64) {
+	const curveSteps = 32
+	var x, y float64
+	add := func(p [8]float64, steps int) {
+		pc := pathPieceType{p: p}
+		x0, y0, _, _ := pc.point(0)
+		for j := 1; j <= steps; j++ {
+			pc.t0, pc.t1 = float64(j-1)/float64(steps), float64(j)/float64(steps)
+			x1, y1, _, _ := pc.point(pc.t1)
+			pc.s = length
+			pc.length = math.Hypot(x1-x0, y1-y0)
+			if pc.length > 0 {
+				pieces = append(pieces, pc)
+				length += pc.length
+			}
+			x0, y0 = x1, y1
+		}
+	}
+	for _, seg := range path {
+		a := seg.Arg
+		switch seg.Cmd {
+		case 'M':
+			x, y = a[0], a[1]
+		case 'L':
+			// A line is a curve with control points at a third of its length
+			dx, dy := (a[0]-x)/3, (a[1]-y)/3
+			add([8]float64{x, y, x + dx, y + dy, x + 2*dx, y + 2*dy, a[0], a[1]}, 1)
+			x, y = a[0], a[1]
+		case 'C':
+			add([8]float64{x, y, a[0], a[1], a[2], a[3], a[4], a[5]}, curveSteps)
+			x, y = a[4], a[5]
+		case 'A':
+			for _, c := range svgArcCurves(x, y, seg) {
+				add([8]float64{x, y, c.Arg[0], c.Arg[1], c.Arg[2], c.Arg[3], c.Arg[4], c.Arg[5]}, curveSteps)
+				x, y = c.Arg[4], c.Arg[5]
+			}
+			x, y = a[4], a[5]
+		}
+	}
+	return
+}
+
+// pathPoint returns the point at the distance s along the flattened path
+// pieces and the unit vector of the direction of the path there
+func pathPoint(pieces []pathPieceType, s float64) (x, y, dx, dy float64) {
+	k := len(pieces) - 1
+	for j, pc := range pieces {
+		if s < pc.s+pc.length {
+			k = j
+			break
+		}
+	}
+	pc := pieces[k]
+	t := pc.t0 + (pc.t1-pc.t0)*math.Max(0, math.Min(1, (s-pc.s)/pc.length))
+	x, y, dx, dy = pc.point(t)
+	if d := math.Hypot(dx, dy); d > 1e-9 {
+		dx, dy = dx/d, dy/d
+	} else {
+		// The direction of a degenerate curve is that of the step
+		x0, y0, _, _ := pc.point(pc.t0)
+		x1, y1, _, _ := pc.point(pc.t1)
+		dx, dy = (x1-x0)/pc.length, (y1-y0)/pc.length
+	}
+	return
+}
+
+// svgArcCurves returns the cubic Bézier curves (Cmd 'C') that approximate
+// the elliptical arc segment seg (Cmd 'A') beginning at (x0, y0). The
+// conversion follows the implementation notes of the SVG specification.
+func svgArcCurves(x0, y0 float64, seg SVGBasicSegmentType) (curves []SVGBasicSegmentType) {
+	rx, ry := math.Abs(seg.Arg[0]), math.Abs(seg.Arg[1])
+	x1, y1 := seg.Arg[4], seg.Arg[5]
+	curve := func(cx0, cy0, cx1, cy1, x, y float64) {
+		curves = append(curves, SVGBasicSegmentType{Cmd: 'C', Arg: [6]float64{cx0, cy0, cx1, cy1, x, y},
+			Class: seg.Class, IsPolygon: seg.IsPolygon})
+	}
+	if x0 == x1 && y0 == y1 {
+		return
+	}
+	if rx == 0 || ry == 0 {
+		// An arc without radius is a straight line
+		curve(x0, y0, x1, y1, x1, y1)
+		return
+	}
+	flags := int(seg.Arg[3])
+	large, sweep := flags&2 != 0, flags&1 != 0
+	phi := seg.Arg[2] * math.Pi / 180
+	cos, sin := math.Cos(phi), math.Sin(phi)
+	// Center of the ellipse
+	dx, dy := (x0-x1)/2, (y0-y1)/2
+	px, py := cos*dx+sin*dy, -sin*dx+cos*dy
+	if l := px*px/(rx*rx) + py*py/(ry*ry); l > 1 {
+		rx, ry = rx*math.Sqrt(l), ry*math.Sqrt(l)
+	}
+	coef := 0.0
+	num := rx*rx*ry*ry - rx*rx*py*py - ry*ry*px*px
+	if den := rx*rx*py*py + ry*ry*px*px; num > 0 && den > 0 {
+		coef = math.Sqrt(num / den)
+	}
+	if large == sweep {
+		coef = -coef
+	}
+	cxp, cyp := coef*rx*py/ry, -coef*ry*px/rx
+	cx, cy := cos*cxp-sin*cyp+(x0+x1)/2, sin*cxp+cos*cyp+(y0+y1)/2
+	// Start angle and extent
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	theta := angle(1, 0, (px-cxp)/rx, (py-cyp)/ry)
+	delta := angle((px-cxp)/rx, (py-cyp)/ry, (-px-cxp)/rx, (-py-cyp)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+	// Each curve spans at most a quarter of the ellipse
+	n := int(math.Ceil(math.Abs(delta)/(math.Pi/2) - 1e-9))
+	if n < 1 {
+		n = 1
+	}
+	step := delta / float64(n)
+	k := 4.0 / 3 * math.Tan(step/4)
+	point := func(t float64) (x, y, dx, dy float64) {
+		ex, ey := rx*math.Cos(t), ry*math.Sin(t)
+		tx, ty := -rx*math.Sin(t), ry*math.Cos(t)
+		return cx + cos*ex - sin*ey, cy + sin*ex + cos*ey, cos*tx - sin*ty, sin*tx + cos*ty
+	}
+	for j := 0; j < n; j++ {
+		ax, ay, adx, ady := point(theta + step*float64(j))
+		bx, by, bdx, bdy := point(theta + step*float64(j+1))
+		if j == n-1 {
+			bx, by = x1, y1
+		}
+		curve(ax+k*adx, ay+k*ady, bx-k*bdx, by-k*bdy, bx, by)
+	}
+	return
+}
+
+// TextAlongPath prints the character string txtStr along path with the
+// current font, font size and text color. Each character is placed with the
+// middle of its baseline on the path and rotated so that the baseline
+// follows the direction of the path at that point. This can be used, for
+// example, to set text along a circle or a wave.
+//
+// path is a sequence of segments as described by SVGBasicSegmentType and
+// returned by SVGBasicParse(), in the unit of measure specified in New().
+// The segments are moves (Cmd 'M'), lines ('L'), cubic Bézier curves ('C')
+// and elliptical arcs ('A'); all coordinates are absolute. The text runs in
+// the direction in which the path is drawn; it is above the path, to the
+// left of that direction. Moves interrupt the path but do not count towards
+// its length.
+//
+// offset is the distance along the path, in the unit of measure specified
+// in New(), of the anchor of the text. alignStr is "L" for text that begins
+// at the anchor, "C" for text centered on it and "R" for text that ends
+// there. spacing is added between characters, in the unit of measure
+// specified in New(), in addition to the character spacing set with
+// SetCharSpacing(). Characters whose middle falls before the beginning or
+// beyond the end of the path are not printed.
+//
+// Kerning, horizontal scaling, text rise, fallback fonts, small capitals
+// and synthetic bold and italic styles are honored; underlines,
+// strikethroughs and overlines are not drawn.
+//
+// See tutorial 51 for an example of this function.
+func (f *Fpdf) TextAlongPath(path []SVGBasicSegmentType, txtStr string, offset float64, alignStr string, spacing float64) {
+	if f.err != nil {
+		return
+	}
+	pieces, length := flattenPath(path)
+	list := f.glyphs(txtStr)
+	if len(pieces) == 0 || len(list) == 0 {
+		return
+	}
+	adv := make([]float64, len(list))
+	wd := spacing * float64(len(list)-1)
+	for j, g := range list {
+		adv[j] = f.glyphAdvance(g) * f.fontSize / 1000
+		wd += adv[j]
+	}
+	s := offset
+	switch alignStr {
+	case "C":
+		s -= wd / 2
+	case "R":
+		s -= wd
+	}
+	k := f.k
+	beginStr, endStr := f.synthBoldOps()
+	var b fmtBuffer
+	b.printf("BT %s", beginStr)
+	key, caps := "", false
+	for j, g := range list {
+		if mid := s + adv[j]/2; mid >= 0 && mid <= length {
+			x, y, dx, dy := pathPoint(pieces, mid)
+			// The text matrix rotates the glyph to the direction of the path,
+			// which is reversed vertically on the page, and slants it for
+			// synthetic italic text
+			cos, sin := dx, -dy
+			c, d := -sin, cos
+			if f.synth.italic {
+				c, d = synthItalicSkew*cos-sin, synthItalicSkew*sin+cos
+			}
+			b.printf("%.5f %.5f %.5f %.5f %.2f %.2f Tm ", cos, sin, c, d,
+				(x-dx*adv[j]/2)*k, (f.h-(y-dy*adv[j]/2))*k)
+			if g.font != key || g.caps != caps {
+				key, caps = g.font, g.caps
+				size := f.fontSizePt
+				if caps {
+					size *= smallCapsScale
+				}
+				b.printf("/F%d %.2f Tf ", f.glyphFont(key).I, size)
+			}
+			// Kerning is included in the position of the next glyph
+			g.kern = 0
+			f.showGlyphs(&b, []glyphType{g}, f.glyphFont(key))
+			b.WriteString(" ")
+		}
+		s += adv[j] + spacing
+	}
+	if key != "" || caps {
+		b.printf("/F%d %.2f Tf ", f.currentFont.I, f.fontSizePt)
+	}
+	b.printf("%sET", endStr)
+	str := b.String()
+	if f.colorFlag {
+		str = sprintf("q %s %s Q", f.color.text.str, str)
+	}
+	f.out(str)
+}
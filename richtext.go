@@ -0,0 +1,352 @@
+package gofpdf
+
+// Paragraphs of styled text runs that are laid out in a box of fixed width.
+
+import (
+	"strings"
+)
+
+// RichTextRunType describes a run of text in which the font, color and link
+// do not vary. It is added to a paragraph with RichTextType.AddRun().
+type RichTextRunType struct {
+	Str              string  // Text of the run; line feeds begin new lines
+	FamilyStr        string  // Font family; empty for the family current when the paragraph is laid out
+	StyleStr         string  // Font style as in SetFont(), for example "B", "IU" or "S"
+	Size             float64 // Font size in points; zero for the size current when the paragraph is laid out
+	ClrR, ClrG, ClrB int     // Text color (0 - 255)
+	Link             int     // Internal link identifier returned by AddLink(), or zero
+	LinkStr          string  // External link used if Link is zero, or empty
+}
+
+// RichTextType is a paragraph built from runs of text that differ in font,
+// size, color, underlining and link. The paragraph can be measured for a given
+// width with Height() and drawn at a position with Draw(). Lines are broken
+// between words. Each line is as high as its tallest run multiplied by the
+// line spacing, and the runs of a line share a common baseline.
+type RichTextType struct {
+	pdf      *Fpdf
+	runs     []RichTextRunType
+	alignStr string
+	spacing  float64
+}
+
+// richTextFragType is a word, a space or a line feed of a single run
+type richTextFragType struct {
+	run       int
+	str       string
+	wd        float64 // width in user units
+	asc, desc float64 // font ascent and descent (positive) in user units
+	size      float64 // font size in user units
+	space     bool
+	feed      bool
+	brk       bool // part of a long word before which a line may be broken
+}
+
+// richTextLineType is a laid out line of a paragraph
+type richTextLineType struct {
+	frags     []richTextFragType
+	wd        float64 // width, excluding the spaces that follow the last word
+	ht        float64 // line height
+	asc, desc float64
+	last      bool // line ends a paragraph
+}
+
+// RichTextNew returns a paragraph of styled text runs associated with the
+// document. alignStr specifies the horizontal alignment of the lines: "L" for
+// left (the default), "C" for center, "R" for right or "J" for justified. The
+// last line of a justified paragraph, and lines that end with a line feed,
+// are left aligned. The line spacing is initially 1.2 times the size of the
+// largest font in each line.
+//
+// See tutorial 38 for an example of this function.
+func (f *Fpdf) RichTextNew(alignStr string) (rt *RichTextType) {
+	return &RichTextType{pdf: f, alignStr: strings.ToUpper(alignStr), spacing: 1.2}
+}
+
+// AddRun appends a styled run of text to the paragraph.
+func (rt *RichTextType) AddRun(run RichTextRunType) {
+	rt.runs = append(rt.runs, run)
+}
+
+// AddText appends a run of black, unlinked text to the paragraph.
+// familyStr, styleStr and size are as in SetFont(); an empty family or a size
+// of zero indicate the font family or size current when the paragraph is
+// laid out.
+func (rt *RichTextType) AddText(familyStr, styleStr string, size float64, txtStr string) {
+	rt.runs = append(rt.runs, RichTextRunType{Str: txtStr, FamilyStr: familyStr,
+		StyleStr: styleStr, Size: size})
+}
+
+// SetLineSpacing sets the height of each line as a multiple of the size of the
+// largest font in the line. The default is 1.2.
+func (rt *RichTextType) SetLineSpacing(factor float64) {
+	rt.spacing = factor
+}
+
+// Height returns the height, in the unit of measure specified in New(), of the
+// paragraph when it is laid out in a box that is w units wide. Nothing is
+// drawn. The font and text color of the document are not changed.
+func (rt *RichTextType) Height(w float64) (ht float64) {
+	for _, line := range rt.layout(w) {
+		ht += line.ht
+	}
+	return
+}
+
+// Draw lays out the paragraph in a box that is w units wide, with its
+// upper-left corner at (x, y), and draws it. The height of the paragraph is
+// returned. Automatic page breaks are not performed; use Height() to ensure
+// that the paragraph fits on the page. The current position, font and text
+// color of the document are not changed.
+func (rt *RichTextType) Draw(x, y, w float64) (ht float64) {
+	f := rt.pdf
+	lines := rt.layout(w)
+	if f.err != nil {
+		return
+	}
+	rt.save(func() {
+		familyStr, sizePt := f.fontFamily, f.fontSizePt
+		for _, line := range lines {
+			rt.drawLine(line, x, y+ht, w, familyStr, sizePt)
+			ht += line.ht
+		}
+	})
+	return
+}
+
+// save calls fnc and then restores the font and text color of the document
+func (rt *RichTextType) save(fnc func()) {
+	f := rt.pdf
+	familyStr, styleStr, sizePt := f.fontFamily, f.fontStyle+f.decorationStyle(), f.fontSizePt
+	clr := f.color.text
+	fnc()
+	if familyStr != "" {
+		f.SetFont(familyStr, styleStr, sizePt)
+	}
+	f.color.text = clr
+	f.colorFlag = f.color.fill.str != f.color.text.str
+}
+
+// selectRun makes the font of run k current and returns its ascent, descent
+// and size in user units
+func (rt *RichTextType) selectRun(k int, familyStr string, sizePt float64) (asc, desc, size float64) {
+	f := rt.pdf
+	run := rt.runs[k]
+	if run.FamilyStr != "" {
+		familyStr = run.FamilyStr
+	}
+	if run.Size != 0 {
+		sizePt = run.Size
+	}
+	f.SetFont(familyStr, run.StyleStr, sizePt)
+	a, d := f.fontAscent()
+	return float64(a) * f.fontSize / 1000, float64(-d) * f.fontSize / 1000, f.fontSize
+}
+
+// fragments splits the runs of the paragraph into words, spaces and line
+// feeds. A word that is wider than w is broken between characters.
+func (rt *RichTextType) fragments(w float64) (frags []richTextFragType) {
+	f := rt.pdf
+	rt.save(func() {
+		familyStr, sizePt := f.fontFamily, f.fontSizePt
+		for k, run := range rt.runs {
+			asc, desc, size := rt.selectRun(k, familyStr, sizePt)
+			if f.err != nil {
+				return
+			}
+			str := strings.Replace(run.Str, "\r", "", -1)
+			for len(str) > 0 {
+				frag := richTextFragType{run: k, asc: asc, desc: desc, size: size}
+				switch str[0] {
+				case '\n':
+					frag.feed = true
+					str = str[1:]
+				case ' ':
+					frag.space = true
+					frag.str = " "
+					str = str[1:]
+				default:
+					end := strings.IndexAny(str, " \n")
+					if end < 0 {
+						end = len(str)
+					}
+					frag.str = str[:end]
+					str = str[end:]
+				}
+				frag.wd = f.GetStringWidth(frag.str)
+				if frag.wd > w && !frag.space {
+					// Break a long word between characters
+					for n, part := range rt.splitWord(frag.str, w) {
+						frag.str = part
+						frag.wd = f.GetStringWidth(part)
+						frag.brk = n > 0
+						frags = append(frags, frag)
+					}
+				} else {
+					frags = append(frags, frag)
+				}
+			}
+		}
+	})
+	return
+}
+
+// layout breaks the paragraph into lines that are at most w units wide. A
+// word that is wider than w is broken between characters.
+func (rt *RichTextType) layout(w float64) (lines []richTextLineType) {
+	f := rt.pdf
+	if f.err != nil {
+		return
+	}
+	// The fonts of the runs are selected to measure the text without writing
+	// font changes to the page
+	var frags []richTextFragType
+	f.silently(func() {
+		frags = rt.fragments(w)
+	})
+	if f.err != nil {
+		return nil
+	}
+	// Fill the lines with whole words; consecutive fragments that are not
+	// separated by a space form a single word
+	var line richTextLineType
+	var pending []richTextFragType // spaces that precede the next word
+	words := 0
+	finish := func(last bool) {
+		line.last = last
+		for _, frag := range line.frags {
+			if frag.asc > line.asc {
+				line.asc = frag.asc
+			}
+			if frag.desc > line.desc {
+				line.desc = frag.desc
+			}
+			if ht := frag.size * rt.spacing; ht > line.ht {
+				line.ht = ht
+			}
+		}
+		lines = append(lines, line)
+		line = richTextLineType{}
+		pending = nil
+		words = 0
+	}
+	for j := 0; j < len(frags); {
+		frag := frags[j]
+		if frag.feed {
+			if len(line.frags) == 0 {
+				line.frags = append(line.frags, frag)
+			}
+			finish(true)
+			j++
+			continue
+		}
+		if frag.space {
+			pending = append(pending, frag)
+			j++
+			continue
+		}
+		end := j + 1
+		wd := frag.wd
+		for end < len(frags) && !frags[end].space && !frags[end].feed && !frags[end].brk {
+			wd += frags[end].wd
+			end++
+		}
+		spaceWd := 0.0
+		for _, p := range pending {
+			spaceWd += p.wd
+		}
+		if words > 0 && line.wd+spaceWd+wd > w {
+			finish(false)
+			spaceWd = 0
+		}
+		line.frags = append(line.frags, pending...)
+		line.frags = append(line.frags, frags[j:end]...)
+		line.wd += spaceWd + wd
+		pending = nil
+		words++
+		j = end
+	}
+	if len(line.frags) > 0 || len(lines) == 0 {
+		finish(true)
+	}
+	return
+}
+
+// splitWord breaks a word of the current font into parts that are at most w
+// units wide, each part containing at least one character
+func (rt *RichTextType) splitWord(str string, w float64) (parts []string) {
+	f := rt.pdf
+	adv := f.advances(str)
+	wmax := w * 1000 / f.fontSize
+	j := 0
+	l := 0.0
+	for i := 0; i < len(str); {
+		_, size := f.nextChar(str, i)
+		if l+adv[i] > wmax && i > j {
+			parts = append(parts, str[j:i])
+			j = i
+			l = 0
+		}
+		l += adv[i]
+		i += size
+	}
+	return append(parts, str[j:])
+}
+
+// drawLine draws a laid out line with its upper-left corner at (x, y).
+// familyStr and sizePt are the font family and size of runs that do not
+// specify them.
+func (rt *RichTextType) drawLine(line richTextLineType, x, y, w float64, familyStr string, sizePt float64) {
+	f := rt.pdf
+	// Spaces that follow the last word are not drawn
+	n := len(line.frags)
+	for n > 0 && line.frags[n-1].space {
+		n--
+	}
+	extra := 0.0 // additional width of each space in a justified line
+	switch rt.alignStr {
+	case "C":
+		x += (w - line.wd) / 2
+	case "R":
+		x += w - line.wd
+	case "J":
+		spaces := 0
+		for _, frag := range line.frags[:n] {
+			if frag.space {
+				spaces++
+			}
+		}
+		if !line.last && spaces > 0 {
+			extra = (w - line.wd) / float64(spaces)
+		}
+	}
+	baseline := y + (line.ht-line.asc-line.desc)/2 + line.asc
+	for _, frag := range line.frags[:n] {
+		if frag.feed {
+			continue
+		}
+		run := rt.runs[frag.run]
+		rt.selectRun(frag.run, familyStr, sizePt)
+		f.SetTextColor(run.ClrR, run.ClrG, run.ClrB)
+		wd := frag.wd
+		if frag.space {
+			wd += extra
+			if f.decorated() {
+				// Decorate the space between decorated words
+				s := f.decorate(x, baseline, wd)
+				if f.colorFlag {
+					s = sprintf("q %s %s Q", f.color.text.str, s)
+				}
+				f.out(s)
+			}
+		} else {
+			f.Text(x, baseline, frag.str)
+		}
+		if run.Link != 0 {
+			f.Link(x, y, wd, line.ht, run.Link)
+		} else if run.LinkStr != "" {
+			f.LinkString(x, y, wd, line.ht, run.LinkStr)
+		}
+		x += wd
+	}
+}
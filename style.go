@@ -61,7 +61,7 @@ func (ce *StyleDef) Append(str string) {
 
 // Extend cureent styledef about data from another styledef
 func (ce *StyleDef) Extend(style *StyleDef) {
-	if ce == nil {
+	if ce == nil || style == nil {
 		return
 	}
 	for k, v := range style.StringMap {
@@ -37,12 +37,16 @@ func init() {
 	pathCmdSub = strings.NewReplacer(",", " ",
 		"L", " L ", "l", " l ",
 		"C", " C ", "c", " c ",
+		"A", " A ", "a", " a ",
 		"M", " M ", "m", " m ")
 	floatFinder = regexp.MustCompile(`\-{0,1}\d+\.\d+`)
 	intFinder = regexp.MustCompile(`\-{0,1}\d+`)
 }
 
-// SVGBasicSegmentType describes a single curve or position segment
+// SVGBasicSegmentType describes a single curve or position segment. The
+// arguments of an elliptical arc segment (Cmd 'A') are the radii rx and ry,
+// the rotation of the x-axis of the ellipse in degrees, the large-arc and
+// sweep flags of SVG combined as 2*large + sweep, and the end point x and y.
 type SVGBasicSegmentType struct {
 	Cmd       byte // See http://www.w3.org/TR/SVG/paths.html for path command structure
 	Arg       [6]float64
@@ -67,7 +71,11 @@ type TextType struct {
 	Text                      []string
 	Class                     string
 	Style                     *StyleDef
+	Path                      []SVGBasicSegmentType // path of a textPath element, nil for ordinary text
+	StartOffset               string                // startOffset of a textPath element, a length or a percentage
+	Anchor                    string                // text-anchor: "start", "middle" or "end"
 	matrix                    []float64
+	pathRef                   string
 	x, y, fontScale, rotation float64
 }
 
@@ -92,15 +100,29 @@ func NewTextType(src srcText) TextType {
 		}
 	}
 	style := NewStyleDef(src.Style)
-	return TextType{Transform: src.Transform,
+	text := TextType{Transform: src.Transform,
 		Text:      src.Text(),
 		Class:     src.Class,
 		Style:     style,
+		Anchor:    src.Anchor,
 		x:         e,
 		y:         f,
 		fontScale: scale,
 		rotation:  rotation,
 	}
+	if anchor, ok := style.StringMap["text-anchor"]; ok {
+		text.Anchor = strings.TrimSpace(anchor)
+	}
+	if tp := src.TextPath; tp != nil {
+		// Text along a path is laid out as a single line
+		text.Text = nil
+		if str := strings.Join(srcText{Tspan: tp.Tspan, Value: tp.Value}.Text(), " "); str != "" {
+			text.Text = []string{str}
+		}
+		text.StartOffset = strings.TrimSpace(tp.StartOffset)
+		text.pathRef = strings.TrimPrefix(strings.TrimSpace(tp.Href), "#")
+	}
+	return text
 }
 
 // XY returns coordinates of text
@@ -123,17 +145,27 @@ type srcRect struct {
 type srcPathType struct {
 	D     string `xml:"d,attr"`
 	Class string `xml:"class,attr"`
+	ID    string `xml:"id,attr"`
 }
 type srcGType struct {
 	Paths []srcPathType `xml:"path"`
 	Texts []srcText     `xml:"text"`
 }
 type srcText struct {
-	Transform string   `xml:"transform,attr"`
-	Tspan     []string `xml:"tspan"`
-	Value     string   `xml:",chardata"`
-	Class     string   `xml:"class,attr"`
-	Style     string   `xml:"style,attr"`
+	Transform string       `xml:"transform,attr"`
+	Tspan     []string     `xml:"tspan"`
+	Value     string       `xml:",chardata"`
+	Class     string       `xml:"class,attr"`
+	Style     string       `xml:"style,attr"`
+	Anchor    string       `xml:"text-anchor,attr"`
+	TextPath  *srcTextPath `xml:"textPath"`
+}
+
+type srcTextPath struct {
+	Href        string   `xml:"href,attr"`
+	StartOffset string   `xml:"startOffset,attr"`
+	Tspan       []string `xml:"tspan"`
+	Value       string   `xml:",chardata"`
 }
 
 func (t srcText) Text() []string {
@@ -157,6 +189,7 @@ type srcType struct {
 	Paths   []srcPathType `xml:"path"`
 	G       []srcGType    `xml:"g"`
 	Styles  []string      `xml:"defs>style"`
+	Defs    []srcPathType `xml:"defs>path"`
 	Texts   []srcText     `xml:"text"`
 }
 
@@ -199,6 +232,14 @@ func absolutizePath(segs []SVGBasicSegmentType) {
 			segPtr.Cmd = 'C'
 			x = segPtr.Arg[4]
 			y = segPtr.Arg[5]
+		case 'A':
+			x = seg.Arg[4]
+			y = seg.Arg[5]
+		case 'a':
+			adjust(4, x, y)
+			segPtr.Cmd = 'A'
+			x = segPtr.Arg[4]
+			y = segPtr.Arg[5]
 		}
 	}
 }
@@ -208,6 +249,7 @@ func pathParse(path srcPathType) (segs []SVGBasicSegmentType, err error) {
 		seg                             SVGBasicSegmentType
 		j, argJ, argCount, prevArgCount int
 		isPolygon                       bool
+		arc                             [7]float64
 	)
 	pathStr := path.D
 	seg.Class = path.Class
@@ -258,9 +300,29 @@ func pathParse(path srcPathType) (segs []SVGBasicSegmentType, err error) {
 					setup(6)
 				case 'L', 'l': // Absolute/relative lineto: x, y
 					setup(2)
+				case 'A', 'a': // Absolute/relative elliptical arc: rx, ry, rotation, large, sweep, x1, y1
+					setup(7)
 				default:
 					//err = fmt.Errorf("expecting SVG path command at position %d, got %s", j, str)
 				}
+			} else if seg.Cmd == 'A' || seg.Cmd == 'a' {
+				arc[argJ], err = strconv.ParseFloat(str, 64)
+				if err == nil {
+					argJ++
+					argCount--
+					if argCount == 0 {
+						// Combine the flags so that the end point is in the
+						// same place as that of a curve
+						seg.Arg = [6]float64{arc[0], arc[1], arc[2], 0, arc[5], arc[6]}
+						if arc[3] != 0 {
+							seg.Arg[3] += 2
+						}
+						if arc[4] != 0 {
+							seg.Arg[3]++
+						}
+						segs = append(segs, seg)
+					}
+				}
 			} else {
 				seg.Arg[argJ], err = strconv.ParseFloat(str, 64)
 				if err == nil {
@@ -290,8 +352,10 @@ func pathParse(path srcPathType) (segs []SVGBasicSegmentType, err error) {
 // descriptor. Only a small subset of the SVG standard, in particular the path
 // information generated by jSignature, is supported. The returned path data
 // includes only the commands 'M' (absolute moveto: x, y), 'L' (absolute
-// lineto: x, y), and 'C' (absolute cubic Bézier curve: cx0, cy0, cx1, cy1,
-// x1,y1).
+// lineto: x, y), 'C' (absolute cubic Bézier curve: cx0, cy0, cx1, cy1,
+// x1,y1) and 'A' (absolute elliptical arc; see SVGBasicSegmentType). Text
+// along a path, given by a textPath element that refers to a path by its id,
+// is supported as well; the path may be defined in the defs element.
 func SVGBasicParse(buf []byte) (sig SVGBasicType, err error) {
 	var (
 		src  srcType
@@ -326,8 +390,18 @@ func SVGBasicParse(buf []byte) (sig SVGBasicType, err error) {
 			}
 		}
 		sig.Styles = NewStylesDef(src.Styles)
+		// Paths defined in defs are not drawn but may be referred to by text
+		paths = append(paths, src.Defs...)
 		for _, text := range texts {
-			sig.Texts = append(sig.Texts, NewTextType((text)))
+			txt := NewTextType((text))
+			if txt.pathRef != "" {
+				for _, path := range paths {
+					if path.ID == txt.pathRef {
+						txt.Path, _ = pathParse(path)
+					}
+				}
+			}
+			sig.Texts = append(sig.Texts, txt)
 		}
 	}
 	return
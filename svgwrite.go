@@ -17,7 +17,9 @@
 package gofpdf
 
 import (
-//	"fmt"
+	//	"fmt"
+	"strconv"
+	"strings"
 )
 
 // SVGBasicWrite renders the paths encoded in the basic SVG image specified by
@@ -70,6 +72,17 @@ func (f *Fpdf) SVGBasicWrite(sb *SVGBasicType, scale float64) {
 				newX, newY = val(4)
 				f.CurveCubic(x, y, cx0, cy0, newX, newY, cx1, cy1, "D")
 				x, y = newX, newY
+			case 'A':
+				arc := seg
+				arc.Arg[0] *= scale
+				arc.Arg[1] *= scale
+				arc.Arg[4], arc.Arg[5] = val(4)
+				for _, curve := range svgArcCurves(x, y, arc) {
+					a := curve.Arg
+					f.CurveBezierCubic(x, y, a[0], a[1], a[2], a[3], a[4], a[5], "D")
+					x, y = a[4], a[5]
+				}
+				x, y = arc.Arg[4], arc.Arg[5]
 			default:
 				f.SetErrorf("Unexpected path command '%c'", seg.Cmd)
 			}
@@ -101,7 +114,10 @@ func (f *Fpdf) SVGWriteTexts(sb *SVGBasicType, scale float64) {
 	}
 }
 
-// SVGWriteText writes, transform and rotate SVG text on PDF document
+// SVGWriteText writes, transform and rotate SVG text on PDF document. Text
+// along a path (see TextType) is written with TextAlongPath(); its start
+// offset and anchor are honored, its font size is scaled like the path and
+// its transformation is ignored.
 func (f *Fpdf) SVGWriteText(sb *SVGBasicType, text TextType, scale float64) {
 	// merge elemet style with class style
 	style := text.Style
@@ -113,6 +129,12 @@ func (f *Fpdf) SVGWriteText(sb *SVGBasicType, text TextType, scale float64) {
 	shiftRatio := text.Style.BaseLineShift / 100.0
 
 	fontSize := style.FontSize * text.FontScale()
+	if text.Path != nil {
+		// The font size is scaled like the path
+		f.SetFontSize(style.FontSize * FontSize * scale * f.k)
+		f.svgWriteTextPath(text, scale)
+		return
+	}
 	f.SetFontSize(fontSize)
 	_, pointsFontSize := f.GetFontSize()
 	yShift := 0.0
@@ -159,3 +181,38 @@ func (f *Fpdf) SetStyle(style *StyleDef) {
 	lineW := f.GetLineWidth()
 	f.SetLineWidth(lineW * style.StrokeWidth)
 }
+
+// svgWriteTextPath writes the text of a textPath element along its path
+func (f *Fpdf) svgWriteTextPath(text TextType, scale float64) {
+	path := make([]SVGBasicSegmentType, len(text.Path))
+	for j, seg := range text.Path {
+		if seg.Cmd == 'A' {
+			seg.Arg[0] *= scale
+			seg.Arg[1] *= scale
+			seg.Arg[4] *= scale
+			seg.Arg[5] *= scale
+		} else {
+			for k := range seg.Arg {
+				seg.Arg[k] *= scale
+			}
+		}
+		path[j] = seg
+	}
+	offset := 0.0
+	if str := text.StartOffset; strings.HasSuffix(str, "%") {
+		if pct, err := strconv.ParseFloat(strings.TrimSuffix(str, "%"), 64); err == nil {
+			_, length := flattenPath(path)
+			offset = pct * length / 100
+		}
+	} else if val, err := strconv.ParseFloat(str, 64); err == nil {
+		offset = val * scale
+	}
+	alignStr := "L"
+	switch text.Anchor {
+	case "middle":
+		alignStr = "C"
+	case "end":
+		alignStr = "R"
+	}
+	f.TextAlongPath(path, strings.Join(text.Text, " "), offset, alignStr, 0)
+}
@@ -0,0 +1,149 @@
+package gofpdf
+
+// Bold, italic and small capital styles emulated with the regular font
+
+import (
+	"math"
+	"unicode"
+)
+
+const (
+	synthBoldStroke = 0.025 // width of the stroke of synthetic bold text, relative to the font size
+	synthItalicSkew = 0.2   // horizontal displacement per unit of height of synthetic italic text
+	smallCapsScale  = 0.75  // size of small capitals relative to the font size
+)
+
+// SetSyntheticStyles enables or disables the emulation of font styles that
+// have not been added for a family. When enabled, SetFont() selects the
+// regular font of a family that lacks the bold or italic style requested,
+// and the bold italic style is derived from the bold or the italic font if
+// one of them is available. Bold is emulated by stroking the outlines of the
+// glyphs with the text color in addition to filling them, and each character
+// is widened by the width of the stroke. Italic is emulated by slanting the
+// glyphs. GetStringWidth(), SplitLines() and the functions that break text
+// into lines take the wider characters into account.
+//
+// Synthetic styles apply to Text(), Cell(), CellFormat(), MultiCell(),
+// Write() and the functions that use them; ClipText(), TextOutline() and
+// ClipTextOutline() use the regular glyphs, and the text rendering mode is
+// replaced by filling and stroking for synthetic bold text only if it is
+// CnTextRenderFill. The regular font is embedded in the document, so that
+// no additional font program is required. Synthetic styles are disabled by
+// default, in which case SetFont() sets an error if the requested style has
+// not been added.
+//
+// Small capitals, which are selected with the "C" style of SetFont(), do not
+// depend on this setting. They are available for all fonts except CJK fonts
+// (see AddCIDFont()): lowercase letters are replaced with capital letters set
+// at three quarters of the font size. For fonts that are not Unicode fonts,
+// only the letters a to z are replaced.
+//
+// See tutorial 50 for an example of this function.
+func (f *Fpdf) SetSyntheticStyles(on bool) {
+	f.synth.on = on
+}
+
+// GetSyntheticStyles returns true if the emulation of missing font styles is
+// enabled with SetSyntheticStyles().
+func (f *Fpdf) GetSyntheticStyles() bool {
+	return f.synth.on
+}
+
+// synthBase returns the style of the font of familyStr from which styleStr
+// is synthesized, loading a registered font if necessary. ok is false if
+// synthetic styles are disabled or no suitable font has been added.
+func (f *Fpdf) synthBase(familyStr, styleStr string) (baseStr string, ok bool) {
+	if !f.synth.on {
+		return
+	}
+	var list []string
+	switch styleStr {
+	case "B", "I":
+		list = []string{""}
+	case "BI":
+		list = []string{"B", "I", ""}
+	}
+	for _, baseStr = range list {
+		if _, ok = f.fonts[familyStr+baseStr]; ok {
+			return
+		}
+		if reg, found := f.fontRegistry[registryFamily(familyStr)+baseStr]; found {
+			f.addRegisteredFont(familyStr, baseStr, reg)
+			return baseStr, f.err == nil
+		}
+	}
+	return "", false
+}
+
+// smallCapGlyphs replaces the glyphs of lowercase letters in list, which is in
+// logical order and has not been subject to substitutions, with the glyphs of
+// the corresponding capital letters when small capitals are selected
+func (f *Fpdf) smallCapGlyphs(list []glyphType) {
+	if !f.synth.smallCaps {
+		return
+	}
+	utf := f.currentFont.utf8
+	for j, g := range list {
+		if len(g.text) != 1 || !unicode.IsLower(g.text[0]) {
+			continue
+		}
+		var wd int
+		if utf == nil {
+			if g.code < 'a' || g.code > 'z' {
+				continue
+			}
+			g.code -= 'a' - 'A'
+			wd = f.currentFont.Cw[g.code]
+		} else {
+			up := unicode.ToUpper(g.text[0])
+			gid, key, font := f.fontGlyph(up)
+			if up == g.text[0] || gid == 0 {
+				continue
+			}
+			g.code, g.font = gid, key
+			wd = font.glyphWidth(gid)
+		}
+		g.wd = int(math.Floor(float64(wd)*smallCapsScale + 0.5))
+		g.caps = true
+		list[j] = g
+	}
+}
+
+// textObject returns a text object that shows txtStr with its origin at (x,
+// y), in points from the lower left corner of the page. The operators that
+// emulate synthetic bold and italic styles are included.
+func (f *Fpdf) textObject(x, y float64, txtStr string) string {
+	if !f.synth.bold && !f.synth.italic {
+		return sprintf("BT %.2f %.2f Td %s ET", x, y, f.showText(txtStr))
+	}
+	var s fmtBuffer
+	if f.synth.italic {
+		s.printf("BT 1 0 %.3f 1 %.2f %.2f Tm ", synthItalicSkew, x, y)
+	} else {
+		s.printf("BT %.2f %.2f Td ", x, y)
+	}
+	beginStr, endStr := f.synthBoldOps()
+	s.printf("%s%s %sET", beginStr, f.showText(txtStr), endStr)
+	return s.String()
+}
+
+// synthBoldOps returns the operators that begin and end synthetic bold text
+// within a text object, each followed by a space, or empty strings if the
+// current font is not emboldened
+func (f *Fpdf) synthBoldOps() (beginStr, endStr string) {
+	if !f.synth.bold {
+		return
+	}
+	// The stroke, in the color of the text, widens each glyph by its width;
+	// character spacing makes room for it
+	sw := f.fontSize * synthBoldStroke
+	clr := f.color.text
+	beginStr = sprintf("%.3f Tc %.2f w %s ", (f.textState.charSpacing+sw)*f.k, sw*f.k,
+		colorValue(clr.ir, clr.ig, clr.ib, "G", "RG").str)
+	endStr = sprintf("%.3f Tc %.2f w %s ", f.textState.charSpacing*f.k, f.lineWidth*f.k, f.color.draw.str)
+	if f.textState.render == CnTextRenderFill {
+		beginStr += sprintf("%d Tr ", CnTextRenderFillStroke)
+		endStr += sprintf("%d Tr ", CnTextRenderFill)
+	}
+	return
+}
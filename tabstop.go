@@ -0,0 +1,180 @@
+package gofpdf
+
+// Tab stops for text printed with MultiCell() and Write()
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// SetTabStops sets the positions at which text that follows a tab character
+// ('\t') is aligned by MultiCell(), Write() and the Write() method of
+// HTMLBasicType, and which SplitLines() takes into account when it breaks
+// text into lines. The stops need not be in order. A tab advances to the
+// first stop beyond the text that precedes it on the line; the text that
+// follows, up to the next tab or the end of the line, is aligned at that
+// stop as specified by its Align field. A tab that has no stop beyond it is
+// treated as a space. Lines that contain tabs are aligned on the left and are
+// not justified, and paragraphs that contain tabs are broken into lines with
+// the "first-fit" algorithm (see SetLineBreakMode()).
+//
+// Call with an empty list to remove the tab stops, which is the default. In
+// this case, tab characters are printed as ordinary characters. The method
+// can be called before the first page is created and the stops are retained
+// from page to page.
+//
+// See tutorial 49 for an example of this function.
+func (f *Fpdf) SetTabStops(stops []TabStopType) {
+	f.tabStops = nil
+	for _, stop := range stops {
+		stop.Align = strings.ToUpper(stop.Align)
+		switch stop.Align {
+		case "R", "C", "D":
+		default:
+			stop.Align = "L"
+		}
+		if stop.Decimal == "" {
+			stop.Decimal = "."
+		}
+		f.tabStops = append(f.tabStops, stop)
+	}
+	// Stops at the same position keep their order
+	sort.SliceStable(f.tabStops, func(i, j int) bool {
+		return f.tabStops[i].Pos < f.tabStops[j].Pos
+	})
+}
+
+// GetTabStops returns the tab stops set with SetTabStops(), sorted by
+// position.
+func (f *Fpdf) GetTabStops() []TabStopType {
+	return append([]TabStopType(nil), f.tabStops...)
+}
+
+// tabbed returns true if s contains tabs that are laid out with tab stops
+func (f *Fpdf) tabbed(s string) bool {
+	return len(f.tabStops) > 0 && strings.Contains(s, "\t")
+}
+
+// tabAdvance returns the advance of the tab at byte position i of s, in
+// thousandths of the font size, when the tab is preceded by text that reaches
+// to pen from the origin of the tab stops. adv holds the advances of s as
+// returned by advances(). The leader of the stop is returned as well.
+func (f *Fpdf) tabAdvance(s string, adv []float64, i int, pen float64) (wd float64, leaderStr string) {
+	for _, stop := range f.tabStops {
+		// Text that already reaches a stop, allowing for rounding, advances to
+		// the next one
+		pos := stop.Pos * 1000 / f.fontSize
+		if pos <= pen+1e-6 {
+			continue
+		}
+		// The text aligned at the stop reaches to the next tab or line break
+		end := i + 1
+		for end < len(s) && s[end] != '\t' && s[end] != '\n' {
+			end++
+		}
+		sum := func(from, to int) (w float64) {
+			for _, a := range adv[from:to] {
+				w += a
+			}
+			return
+		}
+		switch stop.Align {
+		case "R":
+			pos -= sum(i+1, end)
+		case "C":
+			pos -= sum(i+1, end) / 2
+		case "D":
+			if dec := strings.Index(s[i+1:end], stop.Decimal); dec >= 0 {
+				pos -= sum(i+1, i+1+dec)
+			} else {
+				pos -= sum(i+1, end)
+			}
+		}
+		return math.Max(pos-pen, 0), stop.Leader
+	}
+	return f.advances(" ")[0], ""
+}
+
+// tabCellFormat prints a cell like CellFormat(). If txtStr contains tabs and
+// tab stops are set, the tab stops are measured from the abscissa org and the
+// text is aligned by them rather than by the horizontal alignment of
+// alignStr.
+func (f *Fpdf) tabCellFormat(w, h float64, txtStr, borderStr string, ln int, alignStr string, fill bool, link int, linkStr string, org float64) {
+	if !f.tabbed(txtStr) {
+		f.CellFormat(w, h, txtStr, borderStr, ln, alignStr, fill, link, linkStr)
+		return
+	}
+	if f.ws != 0 {
+		f.ws = 0
+		f.putWordSpacing()
+	}
+	if w == 0 {
+		w = f.w - f.rMargin - f.x
+	}
+	// Border and background, which may cause a page or column break that
+	// moves the cell and the origin of the tab stops
+	x := f.x
+	f.CellFormat(w, h, "", borderStr, 0, "", fill, 0, "")
+	if f.err != nil {
+		return
+	}
+	org += f.x - w - x
+	x = f.x - w
+	vAlignStr := "L"
+	if strings.Contains(alignStr, "T") {
+		vAlignStr += "T"
+	} else if strings.Contains(alignStr, "B") {
+		vAlignStr += "B"
+	}
+	scale := f.fontSize / 1000
+	// put prints str beginning at the distance at from org
+	put := func(str string, at float64) {
+		if str != "" {
+			f.x = org + at*scale - f.cMargin
+			f.CellFormat(f.GetStringWidth(str)+2*f.cMargin, h, str, "", 0, vAlignStr, false, link, linkStr)
+		}
+	}
+	overflowStr := f.cellOverflow
+	f.cellOverflow = "visible"
+	adv := f.advances(txtStr)
+	pen := (x + f.cMargin - org) / scale
+	start, startPen := 0, pen
+	for i := 0; i < len(txtStr); i++ {
+		if txtStr[i] != '\t' {
+			pen += adv[i]
+			continue
+		}
+		put(txtStr[start:i], startPen)
+		wd, leaderStr := f.tabAdvance(txtStr, adv, i, pen)
+		if leaderStr != "" {
+			// Leaders are placed on a grid so that they line up from line to
+			// line
+			lw := 0.0
+			for _, a := range f.advances(leaderStr) {
+				lw += a
+			}
+			if lw > 0 {
+				first := math.Ceil(pen / lw)
+				if n := int(math.Floor((pen+wd)/lw) - first); n > 0 {
+					put(strings.Repeat(leaderStr, n), first*lw)
+				}
+			}
+		}
+		pen += wd
+		start, startPen = i+1, pen
+	}
+	put(txtStr[start:], startPen)
+	f.cellOverflow = overflowStr
+	f.lasth = h
+	if ln > 0 {
+		f.y += h
+		if ln == 1 {
+			f.x = f.lMargin
+		} else {
+			f.x = x
+		}
+	} else {
+		f.x = x + w
+	}
+}
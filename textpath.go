@@ -0,0 +1,165 @@
+package gofpdf
+
+// Text drawn as vector outlines rather than with a font
+
+import (
+	"fmt"
+)
+
+// TextOutline draws the character string txtStr as vector outlines. The
+// origin (x, y) is on the left of the first character at the baseline, as
+// with Text(). The glyph contours of the current font are output as path
+// operators, so the text is not written with the font and the font program is
+// not embedded for it. This is useful, for example, for logos and signage set
+// in fonts whose license does not allow embedding.
+//
+// styleStr can be "F" for filled, "D" for outlined only, or "DF" or "FD" for
+// outlined and filled. An empty string will be replaced with "D". Drawing uses
+// the current draw color and line width; filling uses the current fill color
+// rather than the text color. The current font size, character and word
+// spacing, horizontal scaling, text rise, kerning and transformation (see
+// TransformBegin()) are honored.
+//
+// The current font must be a Unicode font with TrueType outlines (see
+// AddUTF8Font()); otherwise an error is set. Characters taken from fallback
+// fonts (see SetFontFallback()) are drawn with the outlines of those fonts.
+// A font that is only used with this method and ClipTextOutline() is
+// described in the document without its font program, so that its license
+// need not allow embedding.
+//
+// See tutorial 47 for an example of this function.
+func (f *Fpdf) TextOutline(x, y float64, txtStr, styleStr string) {
+	path, ok := f.textOutlinePath(x, y, txtStr)
+	if ok && path != "" {
+		f.out(path + fillDrawOp(styleStr))
+	}
+}
+
+// ClipTextOutline begins a clipping operation in which rendering is confined
+// to the outlines of the character string txtStr. It is similar to ClipText()
+// except that the clipping path is made of the glyph contours of the current
+// font, as drawn by TextOutline(), so that the same requirements apply. The
+// origin (x, y) is on the left of the first character at the baseline.
+// outline is true to draw a border with the current draw color and line width
+// centered on the perimeters of the text characters. Only the outer half of
+// the border will be shown. Call ClipEnd() to restore unclipped operations.
+//
+// See tutorial 47 for an example of this function.
+func (f *Fpdf) ClipTextOutline(x, y float64, txtStr string, outline bool) {
+	path, ok := f.textOutlinePath(x, y, txtStr)
+	if !ok {
+		return
+	}
+	f.clipNest++
+	if path == "" {
+		// Nothing is visible through an empty clipping path
+		path = "0 0 m "
+	}
+	f.outf("q %sW %s", path, strIf(outline, "S", "n"))
+}
+
+// textOutlinePath returns the path construction operators of the glyph
+// contours of txtStr written with its origin at (x, y). ok is false, and an
+// error is set, if the current font does not have TrueType outlines.
+func (f *Fpdf) textOutlinePath(x, y float64, txtStr string) (path string, ok bool) {
+	if f.err != nil {
+		return
+	}
+	if utf := f.currentFont.utf8; utf == nil || utf.ttf.CFF {
+		f.err = fmt.Errorf("text outlines require a Unicode font with TrueType outlines")
+		return
+	}
+	var s fmtBuffer
+	k := f.k
+	scale := f.textState.scale / 100
+	pen := 0.0
+	for _, g := range f.glyphs(txtStr) {
+		utf := f.glyphFont(g.font).utf8
+		if utf == nil || utf.ttf.CFF {
+			pen += f.glyphAdvance(g)
+			continue
+		}
+		// Scale from font units to user units
+		unit := utf.k * f.fontSize / 1000
+		if g.caps {
+			unit *= smallCapsScale
+		}
+		ox := x + pen*f.fontSize/1000
+		oy := y - f.textState.rise
+		pt := func(p ttfPointType) (float64, float64) {
+			return (ox + p.x*unit*scale) * k, (f.h - (oy - p.y*unit)) * k
+		}
+		for _, contour := range ttfGlyphContours(utf.glyphData, g.code) {
+			s.WriteString(ttfContourPath(contour, pt))
+		}
+		pen += f.glyphAdvance(g)
+	}
+	return s.String(), true
+}
+
+// ttfContourPath returns the path operators of a closed contour of quadratic
+// Bézier segments, which are converted to cubic segments. pt maps a point to
+// page coordinates.
+func ttfContourPath(contour []ttfPointType, pt func(p ttfPointType) (float64, float64)) string {
+	n := len(contour)
+	if n == 0 {
+		return ""
+	}
+	mid := func(a, b ttfPointType) ttfPointType {
+		return ttfPointType{x: (a.x + b.x) / 2, y: (a.y + b.y) / 2, onCurve: true}
+	}
+	// The contour starts at an on-curve point, which is implied between two
+	// off-curve points if there is none
+	first := -1
+	for j, p := range contour {
+		if p.onCurve {
+			first = j
+			break
+		}
+	}
+	var start ttfPointType
+	if first < 0 {
+		start = mid(contour[n-1], contour[0])
+		first = 0
+	} else {
+		start = contour[first]
+		first++
+	}
+	var s fmtBuffer
+	sx, sy := pt(start)
+	s.printf("%.2f %.2f m ", sx, sy)
+	cur := start
+	var ctrl *ttfPointType
+	quad := func(q, end ttfPointType) {
+		c1x, c1y := pt(ttfPointType{x: cur.x + 2*(q.x-cur.x)/3, y: cur.y + 2*(q.y-cur.y)/3})
+		c2x, c2y := pt(ttfPointType{x: end.x + 2*(q.x-end.x)/3, y: end.y + 2*(q.y-end.y)/3})
+		ex, ey := pt(end)
+		s.printf("%.2f %.2f %.2f %.2f %.2f %.2f c ", c1x, c1y, c2x, c2y, ex, ey)
+		cur = end
+	}
+	for j := 0; j < n; j++ {
+		p := contour[(first+j)%n]
+		switch {
+		case p.onCurve && ctrl == nil:
+			px, py := pt(p)
+			s.printf("%.2f %.2f l ", px, py)
+			cur = p
+		case p.onCurve:
+			quad(*ctrl, p)
+			ctrl = nil
+		case ctrl != nil:
+			quad(*ctrl, mid(*ctrl, p))
+			q := p
+			ctrl = &q
+		default:
+			q := p
+			ctrl = &q
+		}
+	}
+	// Close the contour, possibly with a final curve to the start
+	if ctrl != nil {
+		quad(*ctrl, start)
+	}
+	s.WriteString("h ")
+	return s.String()
+}
@@ -0,0 +1,381 @@
+package gofpdf
+
+// Support for the OpenType layout tables (GPOS and GSUB) and the legacy
+// TrueType kerning table. Tables are read into memory and decoded directly;
+// offsets that point outside of a table are treated as empty structures so
+// that damaged fonts degrade gracefully rather than causing a failure.
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// otLookupType is a lookup of a GPOS or GSUB table. Extension lookups are
+// resolved so that tp is the type of the subtables.
+type otLookupType struct {
+	tp        int
+	subtables [][]byte
+}
+
+// otU16 returns the big-endian unsigned 16-bit value at pos, or zero if pos
+// is out of range.
+func otU16(data []byte, pos int) int {
+	if pos < 0 || pos+2 > len(data) {
+		return 0
+	}
+	return int(binary.BigEndian.Uint16(data[pos:]))
+}
+
+// otU32 returns the big-endian unsigned 32-bit value at pos, or zero if pos
+// is out of range.
+func otU32(data []byte, pos int) int {
+	if pos < 0 || pos+4 > len(data) {
+		return 0
+	}
+	return int(binary.BigEndian.Uint32(data[pos:]))
+}
+
+// otSlice returns the portion of data beginning at pos, or nil if pos is out
+// of range.
+func otSlice(data []byte, pos int) []byte {
+	if pos <= 0 || pos >= len(data) {
+		return nil
+	}
+	return data[pos:]
+}
+
+// otFeatureLookups returns the lookups of a GPOS or GSUB table that are
+// referenced by any of the specified features, in the order in which they
+// are to be applied. extType is the type of extension lookups in the table
+// (9 for GPOS and 7 for GSUB). Script and language distinctions are not made.
+func otFeatureLookups(data []byte, extType int, tags ...string) (list []otLookupType) {
+	for _, idx := range otFeatureIndexes(data, tags...) {
+		list = append(list, otLookup(data, idx, extType))
+	}
+	return
+}
+
+// otFeatureIndexes returns the sorted indexes of the lookups of a GPOS or GSUB
+// table that are referenced by any of the specified features
+func otFeatureIndexes(data []byte, tags ...string) (indexes []int) {
+	features := otSlice(data, otU16(data, 6))
+	want := make(map[string]bool)
+	for _, tag := range tags {
+		want[tag] = true
+	}
+	indexMap := make(map[int]bool)
+	for j, count := 0, otU16(features, 0); j < count; j++ {
+		rec := 2 + 6*j
+		if rec+6 > len(features) {
+			break
+		}
+		if want[string(features[rec:rec+4])] {
+			feature := otSlice(features, otU16(features, rec+4))
+			for k, n := 0, otU16(feature, 2); k < n; k++ {
+				indexMap[otU16(feature, 4+2*k)] = true
+			}
+		}
+	}
+	indexes = make([]int, 0, len(indexMap))
+	for idx := range indexMap {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+	return
+}
+
+// otLookup returns the lookup with the specified index of a GPOS or GSUB
+// table. extType is the type of extension lookups in the table.
+func otLookup(data []byte, idx, extType int) (rec otLookupType) {
+	lookups := otSlice(data, otU16(data, 8))
+	if idx >= otU16(lookups, 0) {
+		return
+	}
+	lookup := otSlice(lookups, otU16(lookups, 2+2*idx))
+	rec.tp = otU16(lookup, 0)
+	for k, n := 0, otU16(lookup, 4); k < n; k++ {
+		sub := otSlice(lookup, otU16(lookup, 6+2*k))
+		if rec.tp == extType && otU16(sub, 0) == 1 {
+			// Extension subtable: the actual type and a 32-bit offset
+			rec.tp = otU16(sub, 2)
+			sub = otSlice(sub, otU32(sub, 4))
+		}
+		if sub != nil {
+			rec.subtables = append(rec.subtables, sub)
+		}
+	}
+	return
+}
+
+// otCoverage returns the glyphs of a coverage table in coverage index order
+func otCoverage(data []byte) (glyphs []uint16) {
+	switch otU16(data, 0) {
+	case 1:
+		for j, n := 0, otU16(data, 2); j < n; j++ {
+			glyphs = append(glyphs, uint16(otU16(data, 4+2*j)))
+		}
+	case 2:
+		for j, n := 0, otU16(data, 2); j < n; j++ {
+			rec := 4 + 6*j
+			start, end := otU16(data, rec), otU16(data, rec+2)
+			for g := start; g <= end; g++ {
+				glyphs = append(glyphs, uint16(g))
+			}
+		}
+	}
+	return
+}
+
+// otClassDef returns the class of each glyph listed in a class definition
+// table. Glyphs that are not listed belong to class zero.
+func otClassDef(data []byte) (classes map[uint16]int) {
+	classes = make(map[uint16]int)
+	switch otU16(data, 0) {
+	case 1:
+		start := otU16(data, 2)
+		for j, n := 0, otU16(data, 4); j < n; j++ {
+			if c := otU16(data, 6+2*j); c != 0 {
+				classes[uint16(start+j)] = c
+			}
+		}
+	case 2:
+		for j, n := 0, otU16(data, 2); j < n; j++ {
+			rec := 4 + 6*j
+			start, end, c := otU16(data, rec), otU16(data, rec+2), otU16(data, rec+4)
+			for g := start; g <= end && c != 0; g++ {
+				classes[uint16(g)] = c
+			}
+		}
+	}
+	return
+}
+
+// otValueRecordSize returns the size in bytes of a GPOS value record with the
+// specified format
+func otValueRecordSize(format int) (size int) {
+	for ; format != 0; format >>= 1 {
+		size += 2 * (format & 1)
+	}
+	return
+}
+
+// kerningType maps a left glyph and a right glyph to the adjustment of the
+// space between them in font units
+type kerningType map[uint16]map[uint16]int16
+
+// set records a kerning pair unless it has already been defined; earlier
+// subtables take precedence over later ones.
+func (k kerningType) set(left, right uint16, val int16) {
+	m, ok := k[left]
+	if !ok {
+		m = make(map[uint16]int16)
+		k[left] = m
+	}
+	if _, ok = m[right]; !ok {
+		m[right] = val
+	}
+}
+
+// gposKerning returns the horizontal pair adjustments of the kern feature of
+// a GPOS table. Only the advance of the first glyph is considered; pairs that
+// involve class zero of the second glyph are ignored.
+func gposKerning(data []byte) (kern kerningType) {
+	const xAdvance = 0x0004
+	kern = make(kerningType)
+	for _, lookup := range otFeatureLookups(data, 9, "kern") {
+		if lookup.tp != 2 {
+			continue
+		}
+		for _, sub := range lookup.subtables {
+			cov := otCoverage(otSlice(sub, otU16(sub, 2)))
+			vf1, vf2 := otU16(sub, 4), otU16(sub, 6)
+			if vf1&xAdvance == 0 {
+				continue
+			}
+			recSize := otValueRecordSize(vf1) + otValueRecordSize(vf2)
+			xPos := otValueRecordSize(vf1 & (xAdvance - 1))
+			switch otU16(sub, 0) {
+			case 1:
+				// Individual pairs
+				for j, first := range cov {
+					if j >= otU16(sub, 8) {
+						break
+					}
+					set := otSlice(sub, otU16(sub, 10+2*j))
+					for k, n := 0, otU16(set, 0); k < n; k++ {
+						rec := 2 + k*(2+recSize)
+						if val := int16(otU16(set, rec+2+xPos)); val != 0 {
+							kern.set(first, uint16(otU16(set, rec)), val)
+						}
+					}
+				}
+			case 2:
+				// Pairs of glyph classes
+				classDef1 := otClassDef(otSlice(sub, otU16(sub, 8)))
+				classDef2 := otClassDef(otSlice(sub, otU16(sub, 10)))
+				count1, count2 := otU16(sub, 12), otU16(sub, 14)
+				members2 := make(map[int][]uint16)
+				for g, c := range classDef2 {
+					members2[c] = append(members2[c], g)
+				}
+				for _, first := range cov {
+					c1 := classDef1[first]
+					if c1 >= count1 {
+						continue
+					}
+					for c2 := 1; c2 < count2; c2++ {
+						val := int16(otU16(sub, 16+(c1*count2+c2)*recSize+xPos))
+						if val != 0 {
+							for _, second := range members2[c2] {
+								kern.set(first, second, val)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	if len(kern) == 0 {
+		kern = nil
+	}
+	return
+}
+
+// kernTableKerning returns the horizontal kerning pairs of a TrueType kern
+// table. Only the Microsoft version of the table with format 0 subtables is
+// supported.
+func kernTableKerning(data []byte) (kern kerningType) {
+	if otU16(data, 0) != 0 {
+		return
+	}
+	kern = make(kerningType)
+	pos := 4
+	for j, n := 0, otU16(data, 2); j < n && pos < len(data); j++ {
+		length, coverage := otU16(data, pos+2), otU16(data, pos+4)
+		// Format 0, horizontal, kerning rather than minimum values, not
+		// cross-stream
+		if coverage>>8 == 0 && coverage&7 == 1 {
+			for k, count := 0, otU16(data, pos+6); k < count; k++ {
+				rec := pos + 14 + 6*k
+				if val := int16(otU16(data, rec+4)); val != 0 {
+					kern.set(uint16(otU16(data, rec)), uint16(otU16(data, rec+2)), val)
+				}
+			}
+		}
+		if length == 0 {
+			break
+		}
+		pos += length
+	}
+	if len(kern) == 0 {
+		kern = nil
+	}
+	return
+}
+
+// substLookupType is a GSUB lookup that consists of single or ligature
+// substitutions. Other types of lookup are not supported.
+type substLookupType struct {
+	single    map[uint16]uint16         // replacement of individual glyphs
+	ligatures map[uint16][]ligatureType // ligatures keyed by their first glyph
+}
+
+// ligatureType describes a glyph that replaces a sequence of glyphs
+type ligatureType struct {
+	components []uint16 // glyphs that follow the first glyph
+	glyph      uint16   // ligature glyph
+}
+
+// gsubSubstitutions returns the single and ligature substitution lookups of
+// a GSUB table that belong to any of the specified features, keyed by lookup
+// index, and the indexes of the lookups that belong to each feature.
+func gsubSubstitutions(data []byte, tags ...string) (lookups map[int]substLookupType, features map[string][]int) {
+	lookups = make(map[int]substLookupType)
+	features = make(map[string][]int)
+	for _, tag := range tags {
+		for _, idx := range otFeatureIndexes(data, tag) {
+			features[tag] = append(features[tag], idx)
+			if _, ok := lookups[idx]; ok {
+				continue
+			}
+			lookup := otLookup(data, idx, 7)
+			var subst substLookupType
+			switch lookup.tp {
+			case 1:
+				subst.single = make(map[uint16]uint16)
+				for _, sub := range lookup.subtables {
+					cov := otCoverage(otSlice(sub, otU16(sub, 2)))
+					for j, g := range cov {
+						// Earlier subtables take precedence
+						if _, ok := subst.single[g]; ok {
+							continue
+						}
+						switch otU16(sub, 0) {
+						case 1:
+							subst.single[g] = uint16(int(g) + int(int16(otU16(sub, 4))))
+						case 2:
+							if j < otU16(sub, 4) {
+								subst.single[g] = uint16(otU16(sub, 6+2*j))
+							}
+						}
+					}
+				}
+			case 4:
+				subst.ligatures = make(map[uint16][]ligatureType)
+				for _, sub := range lookup.subtables {
+					if otU16(sub, 0) != 1 {
+						continue
+					}
+					cov := otCoverage(otSlice(sub, otU16(sub, 2)))
+					for j, first := range cov {
+						if j >= otU16(sub, 4) {
+							break
+						}
+						set := otSlice(sub, otU16(sub, 6+2*j))
+						for k, n := 0, otU16(set, 0); k < n; k++ {
+							lig := otSlice(set, otU16(set, 2+2*k))
+							var rec ligatureType
+							rec.glyph = uint16(otU16(lig, 0))
+							for m, count := 1, otU16(lig, 2); m < count; m++ {
+								rec.components = append(rec.components, uint16(otU16(lig, 2+2*m)))
+							}
+							subst.ligatures[first] = append(subst.ligatures[first], rec)
+						}
+					}
+				}
+			default:
+				continue
+			}
+			lookups[idx] = subst
+		}
+	}
+	return
+}
+
+// ParseGsub reads the standard and discretionary ligatures of the font
+func (t *ttfParser) ParseGsub() (err error) {
+	var data []byte
+	if data, err = t.ReadTable("GSUB"); err == nil {
+		t.rec.gsubLookups, t.rec.gsubFeatures = gsubSubstitutions(data, "liga", "dlig")
+	}
+	// Substitutions are optional
+	err = nil
+	return
+}
+
+// ParseKerning reads the kerning pairs of the font. Pair adjustments of the
+// GPOS table are preferred to those of the legacy kern table.
+func (t *ttfParser) ParseKerning() (err error) {
+	var data []byte
+	if data, err = t.ReadTable("GPOS"); err == nil {
+		t.rec.Kerning = gposKerning(data)
+	}
+	if t.rec.Kerning == nil {
+		if data, err = t.ReadTable("kern"); err == nil {
+			t.rec.Kerning = kernTableKerning(data)
+		}
+	}
+	// Kerning is optional
+	err = nil
+	return
+}
@@ -23,19 +23,28 @@ package gofpdf
 // Port to Go: Kurt Jung, 2013-07-15
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf16"
 )
 
-// TtfType contains metrics of a TrueType font.
+// TtfType contains metrics of a TrueType font. OpenType fonts with either
+// TrueType or PostScript (CFF) outlines are supported.
 type TtfType struct {
 	Embeddable             bool
 	UnitsPerEm             uint16
 	PostScriptName         string
+	FamilyName             string // font family, as grouped in regular, bold, italic and bold italic styles
 	Bold                   bool
+	Italic                 bool
 	ItalicAngle            int16
 	IsFixedPitch           bool
 	TypoAscender           int16
@@ -44,40 +53,59 @@ type TtfType struct {
 	UnderlineThickness     int16
 	Xmin, Ymin, Xmax, Ymax int16
 	CapHeight              int16
+	XHeight                int16
 	Widths                 []uint16
 	Chars                  map[uint16]uint16
+	SupplementaryChars     map[rune]uint16 // glyph of each character beyond the Basic Multilingual Plane
+	CFF                    bool            // font contains PostScript outlines in a CFF table
+	CIDs                   []uint16        // CID of each glyph if the CFF font is CID-keyed
+	// Kerning maps a left glyph and a right glyph to the adjustment of the
+	// space between them in font units
+	Kerning map[uint16]map[uint16]int16
+	// GSUB substitution lookups keyed by index, and the lookup indexes of
+	// each supported feature ("liga", "dlig")
+	gsubLookups  map[int]substLookupType
+	gsubFeatures map[string][]int
 }
 
 type ttfParser struct {
 	rec              TtfType
-	f                *os.File
+	f                io.ReadSeeker
 	tables           map[string]uint32
+	lengths          map[string]uint32
 	numberOfHMetrics uint16
 	numGlyphs        uint16
 }
 
-// TtfParse extracts various metrics from a TrueType font file.
+// TtfParse extracts various metrics from a TrueType or OpenType font file.
 func TtfParse(fileStr string) (TtfRec TtfType, err error) {
-	var t ttfParser
-	t.f, err = os.Open(fileStr)
+	var buf []byte
+	buf, err = ioutil.ReadFile(fileStr)
 	if err != nil {
 		return
 	}
+	return ttfParseBytes(buf)
+}
+
+// ttfParseBytes extracts various metrics from a TrueType font that has been
+// loaded into memory.
+func ttfParseBytes(buf []byte) (TtfRec TtfType, err error) {
+	var t ttfParser
+	t.f = bytes.NewReader(buf)
 	version, err := t.ReadStr(4)
 	if err != nil {
 		return
 	}
 	if version == "OTTO" {
-		err = fmt.Errorf("fonts based on PostScript outlines are not supported")
-		return
-	}
-	if version != "\x00\x01\x00\x00" {
+		t.rec.CFF = true
+	} else if version != "\x00\x01\x00\x00" {
 		err = fmt.Errorf("unrecognized file format")
 		return
 	}
 	numTables := int(t.ReadUShort())
 	t.Skip(3 * 2) // searchRange, entrySelector, rangeShift
 	t.tables = make(map[string]uint32)
+	t.lengths = make(map[string]uint32)
 	var tag string
 	for j := 0; j < numTables; j++ {
 		tag, err = t.ReadStr(4)
@@ -86,8 +114,8 @@ func TtfParse(fileStr string) (TtfRec TtfType, err error) {
 		}
 		t.Skip(4) // checkSum
 		offset := t.ReadULong()
-		t.Skip(4) // length
 		t.tables[tag] = offset
+		t.lengths[tag] = t.ReadULong()
 	}
 	if err = t.ParseHead(); err != nil {
 		return
@@ -113,7 +141,17 @@ func TtfParse(fileStr string) (TtfRec TtfType, err error) {
 	if err = t.ParsePost(); err != nil {
 		return
 	}
-	t.f.Close()
+	if t.rec.CFF {
+		if err = t.ParseCFF(); err != nil {
+			return
+		}
+	}
+	if err = t.ParseKerning(); err != nil {
+		return
+	}
+	if err = t.ParseGsub(); err != nil {
+		return
+	}
 	TtfRec = t.rec
 	return
 }
@@ -180,6 +218,7 @@ func (t *ttfParser) ParseCmap() (err error) {
 	t.Skip(2) // version
 	numTables := int(t.ReadUShort())
 	offset31 := int64(0)
+	offset310 := int64(0)
 	for j := 0; j < numTables; j++ {
 		platformID := t.ReadUShort()
 		encodingID := t.ReadUShort()
@@ -187,16 +226,35 @@ func (t *ttfParser) ParseCmap() (err error) {
 		if platformID == 3 && encodingID == 1 {
 			offset31 = offset
 		}
+		if platformID == 3 && encodingID == 10 {
+			offset310 = offset
+		}
 	}
-	if offset31 == 0 {
+	if offset31 == 0 && offset310 == 0 {
 		err = fmt.Errorf("no Unicode encoding found")
 		return
 	}
+	t.rec.Chars = make(map[uint16]uint16)
+	t.rec.SupplementaryChars = make(map[rune]uint16)
+	if offset31 != 0 {
+		if err = t.parseCmap4(offset31); err != nil {
+			return
+		}
+	}
+	if offset310 != 0 {
+		err = t.parseCmap12(offset310)
+	}
+	return
+}
+
+// parseCmap4 reads the characters of the Basic Multilingual Plane from the
+// format 4 subtable at offset in the cmap table
+func (t *ttfParser) parseCmap4(offset31 int64) (err error) {
+	var offset int64
 	startCount := make([]uint16, 0, 8)
 	endCount := make([]uint16, 0, 8)
 	idDelta := make([]int16, 0, 8)
 	idRangeOffset := make([]uint16, 0, 8)
-	t.rec.Chars = make(map[uint16]uint16)
 	t.f.Seek(int64(t.tables["cmap"])+offset31, os.SEEK_SET)
 	format := t.ReadUShort()
 	if format != 4 {
@@ -252,33 +310,92 @@ func (t *ttfParser) ParseCmap() (err error) {
 	return
 }
 
+// parseCmap12 reads the characters of the format 12 subtable at offset in the
+// cmap table. Characters of the Basic Multilingual Plane are only added if
+// they are not mapped already.
+func (t *ttfParser) parseCmap12(offset int64) (err error) {
+	t.f.Seek(int64(t.tables["cmap"])+offset, os.SEEK_SET)
+	format := t.ReadUShort()
+	if format != 12 {
+		err = fmt.Errorf("unexpected subtable format: %d", format)
+		return
+	}
+	t.Skip(2 + 4 + 4) // reserved, length, language
+	numGroups := int64(t.ReadULong())
+	if offset+16+12*numGroups > int64(t.lengths["cmap"]) {
+		err = fmt.Errorf("cmap subtable exceeds table")
+		return
+	}
+	for j := int64(0); j < numGroups; j++ {
+		start := t.ReadULong()
+		end := t.ReadULong()
+		gid := t.ReadULong()
+		if end > unicode.MaxRune || start > end {
+			err = fmt.Errorf("invalid character range in cmap subtable")
+			return
+		}
+		for c := start; c <= end; c, gid = c+1, gid+1 {
+			if gid == 0 || gid > 0xFFFF {
+				continue
+			}
+			if c > 0xFFFF {
+				t.rec.SupplementaryChars[rune(c)] = uint16(gid)
+			} else if _, ok := t.rec.Chars[uint16(c)]; !ok {
+				t.rec.Chars[uint16(c)] = uint16(gid)
+			}
+		}
+	}
+	return
+}
+
 func (t *ttfParser) ParseName() (err error) {
 	err = t.Seek("name")
 	if err == nil {
 		tableOffset, _ := t.f.Seek(0, os.SEEK_CUR)
 		t.rec.PostScriptName = ""
+		t.rec.FamilyName = ""
+		familyEnglish := false
 		t.Skip(2) // format
 		count := t.ReadUShort()
 		stringOffset := t.ReadUShort()
-		for j := uint16(0); j < count && t.rec.PostScriptName == ""; j++ {
-			t.Skip(3 * 2) // platformID, encodingID, languageID
+		for j := uint16(0); j < count; j++ {
+			platformID := t.ReadUShort()
+			t.Skip(2) // encodingID
+			languageID := t.ReadUShort()
 			nameID := t.ReadUShort()
 			length := t.ReadUShort()
 			offset := t.ReadUShort()
-			if nameID == 6 {
-				// PostScript name
+			// Windows names in American English are preferred for the family
+			english := platformID == 3 && languageID == 0x409
+			if nameID == 6 && t.rec.PostScriptName == "" || nameID == 1 && (t.rec.FamilyName == "" || english && !familyEnglish) {
+				recPos, _ := t.f.Seek(0, os.SEEK_CUR)
 				t.f.Seek(int64(tableOffset)+int64(stringOffset)+int64(offset), os.SEEK_SET)
 				var s string
 				s, err = t.ReadStr(int(length))
 				if err != nil {
 					return
 				}
-				s = strings.Replace(s, "\x00", "", -1)
-				var re *regexp.Regexp
-				if re, err = regexp.Compile("[(){}<> /%[\\]]"); err != nil {
-					return
+				t.f.Seek(recPos, os.SEEK_SET)
+				if nameID == 6 {
+					// PostScript name
+					s = strings.Replace(s, "\x00", "", -1)
+					var re *regexp.Regexp
+					if re, err = regexp.Compile("[(){}<> /%[\\]]"); err != nil {
+						return
+					}
+					t.rec.PostScriptName = re.ReplaceAllString(s, "")
+				} else {
+					// Family name; Unicode and Windows names are encoded in UTF-16BE
+					if platformID == 0 || platformID == 3 {
+						codes := make([]uint16, len(s)/2)
+						for k := range codes {
+							codes[k] = uint16(s[2*k])<<8 | uint16(s[2*k+1])
+						}
+						s = string(utf16.Decode(codes))
+					}
+					t.rec.FamilyName = strings.TrimSpace(s)
+					familyEnglish = english
 				}
-				t.rec.PostScriptName = re.ReplaceAllString(s, "")
 			}
 		}
 		if t.rec.PostScriptName == "" {
@@ -298,13 +415,16 @@ func (t *ttfParser) ParseOS2() (err error) {
 		t.Skip(11*2 + 10 + 4*4 + 4)
 		fsSelection := t.ReadUShort()
 		t.rec.Bold = (fsSelection & 32) != 0
+		t.rec.Italic = (fsSelection & 1) != 0
 		t.Skip(2 * 2) // usFirstCharIndex, usLastCharIndex
 		t.rec.TypoAscender = t.ReadShort()
 		t.rec.TypoDescender = t.ReadShort()
 		if version >= 2 {
-			t.Skip(3*2 + 2*4 + 2)
+			t.Skip(3*2 + 2*4)
+			t.rec.XHeight = t.ReadShort()
 			t.rec.CapHeight = t.ReadShort()
 		} else {
+			t.rec.XHeight = 0
 			t.rec.CapHeight = 0
 		}
 	}
@@ -324,6 +444,227 @@ func (t *ttfParser) ParsePost() (err error) {
 	return
 }
 
+// ParseCFF reads the PostScript outline table of an OpenType font. Metrics
+// are taken from the other tables; the glyph count is verified and, for
+// CID-keyed fonts, the CID of each glyph is obtained from the charset.
+func (t *ttfParser) ParseCFF() (err error) {
+	var data []byte
+	if data, err = t.ReadTable("CFF "); err != nil {
+		return
+	}
+	if len(data) < 4 || data[0] != 1 {
+		err = fmt.Errorf("unsupported CFF table version")
+		return
+	}
+	// The header is followed by the Name INDEX and the Top DICT INDEX
+	var list [][]byte
+	pos := int(data[2])
+	if _, pos, err = cffIndex(data, pos); err != nil {
+		return
+	}
+	if list, _, err = cffIndex(data, pos); err != nil {
+		return
+	}
+	if len(list) != 1 {
+		err = fmt.Errorf("CFF table must contain exactly one font")
+		return
+	}
+	var top map[int][]float64
+	if top, err = cffDict(list[0]); err != nil {
+		return
+	}
+	ofs, ok := top[17] // CharStrings
+	if !ok || len(ofs) != 1 {
+		err = fmt.Errorf("CFF font has no glyphs")
+		return
+	}
+	if list, _, err = cffIndex(data, int(ofs[0])); err != nil {
+		return
+	}
+	if len(list) != int(t.numGlyphs) {
+		err = fmt.Errorf("CFF glyph count %d does not match font glyph count %d", len(list), t.numGlyphs)
+		return
+	}
+	if _, ok = top[1230]; ok { // ROS: CID-keyed font
+		ofs = top[15] // charset
+		if len(ofs) != 1 {
+			err = fmt.Errorf("CID-keyed CFF font has no charset")
+			return
+		}
+		t.rec.CIDs, err = cffCharset(data, int(ofs[0]), len(list))
+	}
+	return
+}
+
+// cffIndex returns the elements of the CFF INDEX structure that begins at
+// position pos of data and the position that follows it
+func cffIndex(data []byte, pos int) (list [][]byte, next int, err error) {
+	errIndex := fmt.Errorf("invalid CFF index")
+	if pos < 0 || pos+2 > len(data) {
+		err = errIndex
+		return
+	}
+	count := int(binary.BigEndian.Uint16(data[pos:]))
+	pos += 2
+	if count == 0 {
+		next = pos
+		return
+	}
+	if pos >= len(data) {
+		err = errIndex
+		return
+	}
+	offSize := int(data[pos])
+	pos++
+	if offSize < 1 || offSize > 4 || pos+(count+1)*offSize > len(data) {
+		err = errIndex
+		return
+	}
+	offset := func(j int) (val int) {
+		for _, b := range data[pos+j*offSize : pos+(j+1)*offSize] {
+			val = val<<8 | int(b)
+		}
+		return
+	}
+	// Offsets are relative to the byte that precedes the object data
+	base := pos + (count+1)*offSize - 1
+	list = make([][]byte, count)
+	for j := range list {
+		start, end := base+offset(j), base+offset(j+1)
+		if start > end || end > len(data) {
+			err = errIndex
+			return
+		}
+		list[j] = data[start:end]
+	}
+	next = base + offset(count)
+	return
+}
+
+// cffDict returns the operands of each operator in a CFF DICT structure.
+// Two-byte operators are keyed by 1200 plus their second byte.
+func cffDict(data []byte) (dict map[int][]float64, err error) {
+	dict = make(map[int][]float64)
+	var operands []float64
+	for pos := 0; pos < len(data); {
+		b0 := int(data[pos])
+		switch {
+		case b0 <= 21:
+			op := b0
+			pos++
+			if b0 == 12 {
+				if pos >= len(data) {
+					err = fmt.Errorf("invalid CFF dictionary")
+					return
+				}
+				op = 1200 + int(data[pos])
+				pos++
+			}
+			dict[op] = operands
+			operands = nil
+			continue
+		case b0 == 28 && pos+3 <= len(data):
+			operands = append(operands, float64(int16(binary.BigEndian.Uint16(data[pos+1:]))))
+			pos += 3
+		case b0 == 29 && pos+5 <= len(data):
+			operands = append(operands, float64(int32(binary.BigEndian.Uint32(data[pos+1:]))))
+			pos += 5
+		case b0 == 30:
+			var val float64
+			if val, pos, err = cffReal(data, pos+1); err != nil {
+				return
+			}
+			operands = append(operands, val)
+		case b0 >= 32 && b0 <= 246:
+			operands = append(operands, float64(b0-139))
+			pos++
+		case b0 >= 247 && b0 <= 250 && pos+2 <= len(data):
+			operands = append(operands, float64((b0-247)*256+int(data[pos+1])+108))
+			pos += 2
+		case b0 >= 251 && b0 <= 254 && pos+2 <= len(data):
+			operands = append(operands, float64(-(b0-251)*256-int(data[pos+1])-108))
+			pos += 2
+		default:
+			err = fmt.Errorf("invalid CFF dictionary")
+			return
+		}
+	}
+	return
+}
+
+// cffReal decodes the nibbles of a real number operand in a CFF DICT
+func cffReal(data []byte, pos int) (val float64, next int, err error) {
+	var s []byte
+	for ; pos < len(data); pos++ {
+		for _, nibble := range []byte{data[pos] >> 4, data[pos] & 15} {
+			switch {
+			case nibble <= 9:
+				s = append(s, '0'+nibble)
+			case nibble == 0xa:
+				s = append(s, '.')
+			case nibble == 0xb:
+				s = append(s, 'E')
+			case nibble == 0xc:
+				s = append(s, 'E', '-')
+			case nibble == 0xe:
+				s = append(s, '-')
+			case nibble == 0xf:
+				val, err = strconv.ParseFloat(string(s), 64)
+				next = pos + 1
+				return
+			}
+		}
+	}
+	err = fmt.Errorf("invalid CFF real number")
+	return
+}
+
+// cffCharset returns the identifier (SID or CID) of each glyph from the CFF
+// charset that begins at position pos of data
+func cffCharset(data []byte, pos, numGlyphs int) (ids []uint16, err error) {
+	errCharset := fmt.Errorf("invalid CFF charset")
+	if pos <= 2 || pos >= len(data) {
+		// Predefined charsets are not used by CID-keyed fonts
+		err = errCharset
+		return
+	}
+	ids = make([]uint16, numGlyphs)
+	format := data[pos]
+	pos++
+	for gid := 1; gid < numGlyphs; {
+		switch format {
+		case 0:
+			if pos+2 > len(data) {
+				err = errCharset
+				return
+			}
+			ids[gid] = binary.BigEndian.Uint16(data[pos:])
+			pos += 2
+			gid++
+		case 1, 2:
+			var first, left int
+			if format == 1 && pos+3 <= len(data) {
+				first, left = int(binary.BigEndian.Uint16(data[pos:])), int(data[pos+2])
+				pos += 3
+			} else if format == 2 && pos+4 <= len(data) {
+				first, left = int(binary.BigEndian.Uint16(data[pos:])), int(binary.BigEndian.Uint16(data[pos+2:]))
+				pos += 4
+			} else {
+				err = errCharset
+				return
+			}
+			for j := 0; j <= left && gid < numGlyphs; j++ {
+				ids[gid] = uint16(first + j)
+				gid++
+			}
+		default:
+			err = errCharset
+			return
+		}
+	}
+	return
+}
+
 func (t *ttfParser) Seek(tag string) (err error) {
 	ofs, ok := t.tables[tag]
 	if ok {
@@ -334,6 +675,15 @@ func (t *ttfParser) Seek(tag string) (err error) {
 	return
 }
 
+// ReadTable returns the contents of the specified table
+func (t *ttfParser) ReadTable(tag string) (data []byte, err error) {
+	if err = t.Seek(tag); err == nil {
+		data = make([]byte, t.lengths[tag])
+		_, err = io.ReadFull(t.f, data)
+	}
+	return
+}
+
 func (t *ttfParser) Skip(n int) {
 	t.f.Seek(int64(n), os.SEEK_CUR)
 }
@@ -366,3 +716,171 @@ func (t *ttfParser) ReadULong() (val uint32) {
 	binary.Read(t.f, binary.BigEndian, &val)
 	return
 }
+
+// ttfPointType is a point of a glyph outline in font units
+type ttfPointType struct {
+	x, y    float64
+	onCurve bool
+}
+
+// ttfGlyphContours returns the closed contours that make up the outline of
+// glyph gid in the glyf table of a TrueType font. glyphData returns the glyf
+// data of a glyph, or nil if the glyph is empty. The components of composite
+// glyphs are resolved to a nesting depth of eight.
+func ttfGlyphContours(glyphData func(gid uint16) []byte, gid uint16) [][]ttfPointType {
+	return ttfContours(glyphData, gid, 0)
+}
+
+func ttfContours(glyphData func(gid uint16) []byte, gid uint16, depth int) (contours [][]ttfPointType) {
+	g := glyphData(gid)
+	if len(g) < 10 || depth > 8 {
+		return
+	}
+	n := int(int16(binary.BigEndian.Uint16(g)))
+	if n >= 0 {
+		return ttfSimpleContours(g, n)
+	}
+	// Composite glyph
+	const (
+		argsAreWords   = 0x0001
+		argsAreXY      = 0x0002
+		haveScale      = 0x0008
+		moreComponents = 0x0020
+		haveXYScale    = 0x0040
+		haveTwoByTwo   = 0x0080
+	)
+	pos := 10
+	word := func() int {
+		v := int(int16(binary.BigEndian.Uint16(g[pos:])))
+		pos += 2
+		return v
+	}
+	f2dot14 := func() float64 {
+		return float64(word()) / 16384
+	}
+	for flags := moreComponents; flags&moreComponents != 0; {
+		if pos+4 > len(g) {
+			return
+		}
+		flags = int(binary.BigEndian.Uint16(g[pos:]))
+		component := binary.BigEndian.Uint16(g[pos+2:])
+		pos += 4
+		var dx, dy float64
+		if flags&argsAreWords != 0 {
+			if pos+4 > len(g) {
+				return
+			}
+			dx, dy = float64(word()), float64(word())
+		} else {
+			if pos+2 > len(g) {
+				return
+			}
+			dx, dy = float64(int8(g[pos])), float64(int8(g[pos+1]))
+			pos += 2
+		}
+		if flags&argsAreXY == 0 {
+			// Components positioned by matching points are not supported
+			dx, dy = 0, 0
+		}
+		a, b, c, d := 1.0, 0.0, 0.0, 1.0
+		switch {
+		case flags&haveScale != 0 && pos+2 <= len(g):
+			a = f2dot14()
+			d = a
+		case flags&haveXYScale != 0 && pos+4 <= len(g):
+			a, d = f2dot14(), f2dot14()
+		case flags&haveTwoByTwo != 0 && pos+8 <= len(g):
+			a, b, c, d = f2dot14(), f2dot14(), f2dot14(), f2dot14()
+		}
+		for _, contour := range ttfContours(glyphData, component, depth+1) {
+			for j, pt := range contour {
+				contour[j].x = a*pt.x + c*pt.y + dx
+				contour[j].y = b*pt.x + d*pt.y + dy
+			}
+			contours = append(contours, contour)
+		}
+	}
+	return
+}
+
+// ttfSimpleContours decodes the n contours of a simple glyph
+func ttfSimpleContours(g []byte, n int) (contours [][]ttfPointType) {
+	const (
+		onCurve  = 0x01
+		xShort   = 0x02
+		yShort   = 0x04
+		repeat   = 0x08
+		xSamePos = 0x10
+		ySamePos = 0x20
+	)
+	pos := 10
+	if pos+2*n+2 > len(g) || n == 0 {
+		return
+	}
+	ends := make([]int, n)
+	for j := range ends {
+		ends[j] = int(binary.BigEndian.Uint16(g[pos:]))
+		pos += 2
+	}
+	count := ends[n-1] + 1
+	pos += 2 + int(binary.BigEndian.Uint16(g[pos:])) // instructions
+	flags := make([]byte, 0, count)
+	for len(flags) < count {
+		if pos >= len(g) {
+			return nil
+		}
+		fl := g[pos]
+		pos++
+		flags = append(flags, fl)
+		if fl&repeat != 0 && pos < len(g) {
+			for k := int(g[pos]); k > 0 && len(flags) < count; k-- {
+				flags = append(flags, fl)
+			}
+			pos++
+		}
+	}
+	points := make([]ttfPointType, count)
+	// coords decodes the x or y coordinates, which are stored as deltas
+	coords := func(short, same byte, set func(pt *ttfPointType, v float64)) bool {
+		v := 0
+		for j, fl := range flags {
+			switch {
+			case fl&short != 0:
+				if pos >= len(g) {
+					return false
+				}
+				if fl&same != 0 {
+					v += int(g[pos])
+				} else {
+					v -= int(g[pos])
+				}
+				pos++
+			case fl&same == 0:
+				if pos+2 > len(g) {
+					return false
+				}
+				v += int(int16(binary.BigEndian.Uint16(g[pos:])))
+				pos += 2
+			}
+			set(&points[j], float64(v))
+		}
+		return true
+	}
+	if !coords(xShort, xSamePos, func(pt *ttfPointType, v float64) { pt.x = v }) ||
+		!coords(yShort, ySamePos, func(pt *ttfPointType, v float64) { pt.y = v }) {
+		return nil
+	}
+	start := 0
+	for j, end := range ends {
+		if end < start || end >= count {
+			return nil
+		}
+		contour := points[start : end+1 : end+1]
+		for k := range contour {
+			contour[k].onCurve = flags[start+k]&onCurve != 0
+		}
+		contours = append(contours, contour)
+		start = ends[j] + 1
+	}
+	return
+}
@@ -20,6 +20,8 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/jung-kurt/gofpdf"
+	"io/ioutil"
+	"os"
 	// "testing"
 )
 
@@ -71,3 +73,112 @@ func ExampleFpdf_GetStringWidth() {
 	// "\xe4\xb8\x96\xe7\x95\x8c":      width 13.95, bytes  6, runes  2
 	// "\xe7\x61\x20\x76\x61\x3f":      width 12.47, bytes  6, runes  6
 }
+
+// This example demonstrates the parsing of an OpenType font with PostScript
+// (CFF) outlines.
+func ExampleTtfParse_openType() {
+	ttf, err := gofpdf.TtfParse(cnFontDir + "/CFFTest.otf")
+	if err == nil {
+		fmt.Printf("Postscript name:  %s\n", ttf.PostScriptName)
+		fmt.Printf("CFF outlines:     %8v\n", ttf.CFF)
+		fmt.Printf("unitsPerEm:       %8d\n", ttf.UnitsPerEm)
+		fmt.Printf("Glyphs:           %8d\n", len(ttf.Widths))
+		fmt.Printf("Width of \"Q\":     %8d\n", ttf.Widths[ttf.Chars['Q']])
+	} else {
+		fmt.Printf("%s\n", err)
+	}
+	// Output:
+	// Postscript name:  CFFTest
+	// CFF outlines:         true
+	// unitsPerEm:           1000
+	// Glyphs:                  5
+	// Width of "Q":         1000
+}
+
+// This example demonstrates a character beyond the Basic Multilingual Plane,
+// which is mapped by the format 12 subtable of the character map.
+func ExampleTtfParse_supplementary() {
+	ttf, err := gofpdf.TtfParse(cnFontDir + "/DejaVuSans.ttf")
+	if err == nil {
+		gid := ttf.SupplementaryChars['\U0001D538']
+		fmt.Printf("Glyph of U+1D538:  %8d\n", gid)
+		fmt.Printf("Width of U+1D538:  %8d\n", ttf.Widths[gid])
+	} else {
+		fmt.Printf("%s\n", err)
+	}
+	pdf := gofpdf.New("", "", "", cnFontDir)
+	pdf.AddUTF8Font("DejaVu", "", "DejaVuSans.ttf")
+	pdf.SetFont("DejaVu", "", 20)
+	fmt.Printf("Width in document: %8.2f\n", pdf.GetStringWidth("\U0001D538"))
+	// Output:
+	// Glyph of U+1D538:      5495
+	// Width of U+1D538:      1517
+	// Width in document:     5.23
+}
+
+// This example demonstrates that a font with a truncated maxp table results
+// in an error rather than a panic when the document is output.
+func ExampleFpdf_AddUTF8FontFromBytes() {
+	fontBytes, err := ioutil.ReadFile(cnFontDir + "/DejaVuSans.ttf")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	// Reduce the recorded length of the maxp table to four bytes
+	numTables := int(fontBytes[4])<<8 | int(fontBytes[5])
+	for j := 0; j < numTables; j++ {
+		rec := fontBytes[12+16*j:]
+		if string(rec[:4]) == "maxp" {
+			copy(rec[12:16], []byte{0, 0, 0, 4})
+		}
+	}
+	pdf := gofpdf.New("", "", "", "")
+	pdf.AddUTF8FontFromBytes("DejaVu", "", fontBytes)
+	pdf.AddPage()
+	pdf.SetFont("DejaVu", "", 12)
+	pdf.Text(20, 20, "Hello")
+	var buf bytes.Buffer
+	err = pdf.Output(&buf)
+	fmt.Println(err)
+	// Output:
+	// maxp table is truncated
+}
+
+// This example demonstrates that the kerning pairs of a TrueType font are
+// stored in the definition file generated by MakeFont(), so that a font
+// loaded with AddFont() is measured like the same font loaded with
+// AddUTF8Font().
+func ExampleFpdf_SetKerning() {
+	dirStr, err := ioutil.TempDir("", "gofpdf")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer os.RemoveAll(dirStr)
+	err = gofpdf.MakeFont(cnFontDir+"/DejaVuSans.ttf", cnFontDir+"/cp1252.map", dirStr, nil, false)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	pdf := gofpdf.New("", "", "", dirStr)
+	pdf.AddFont("DejaVu", "", "DejaVuSans.json")
+	pdf.SetFontLocation(cnFontDir)
+	pdf.AddUTF8Font("DejaVuUnicode", "", "DejaVuSans.ttf")
+	pdf.AddPage()
+	for _, kern := range []bool{false, true} {
+		pdf.SetKerning(kern)
+		for _, familyStr := range []string{"DejaVu", "DejaVuUnicode"} {
+			pdf.SetFont(familyStr, "", 20)
+			fmt.Printf("%-14s kerning %-5v width %6.2f\n", familyStr, kern, pdf.GetStringWidth("AVATAR To"))
+		}
+	}
+	if pdf.Err() {
+		fmt.Println(pdf.Error())
+	}
+	pdf.Close()
+	// Output:
+	// DejaVu         kerning false width  39.39
+	// DejaVuUnicode  kerning false width  39.39
+	// DejaVu         kerning true  width  36.19
+	// DejaVuUnicode  kerning true  width  36.19
+}
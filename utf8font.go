@@ -0,0 +1,750 @@
+package gofpdf
+
+// Unicode TrueType fonts are embedded as Type0 fonts with a CIDFontType2
+// descendant, Identity-H encoding and a ToUnicode CMap. Glyph indexes are used
+// directly as character codes so only the glyphs that are actually used in the
+// document need to be retained in the embedded font program. OpenType fonts
+// with PostScript outlines use a CIDFontType0 descendant and are embedded in
+// full.
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// utf8FontType contains the data needed to render and embed a TrueType font
+// with Unicode encoding.
+type utf8FontType struct {
+	data []byte            // raw font file
+	ttf  TtfType           // parsed font metrics
+	k    float64           // scale from font units to glyph space units
+	used map[uint16][]rune // glyphs used in document and the text they represent
+	glyf []byte            // glyph outline table, loaded on first use
+	loca []uint32          // offset of each glyph in glyf, with a final end offset
+}
+
+// glyphType describes a single glyph of a string that is prepared for output
+type glyphType struct {
+	code uint16 // character code for single-byte fonts, glyph index for Unicode fonts
+	wd   int    // advance width in thousandths of the font size
+	kern int    // adjustment of the space to the next glyph, same units as wd
+	text []rune // source characters represented by the glyph
+	pos  int    // byte position of the first source character in the text
+	font string // key of the fallback font that contains the glyph, empty for the current font
+	caps bool   // capital letter that replaces a lowercase letter in small capitals
+}
+
+// glyphIndex returns the index of the glyph that represents r, or zero (the
+// missing glyph) if the font does not contain it.
+func (u *utf8FontType) glyphIndex(r rune) (gid uint16) {
+	if r >= 0 && r <= 0xFFFF {
+		gid = u.ttf.Chars[uint16(r)]
+	} else {
+		gid = u.ttf.SupplementaryChars[r]
+	}
+	return
+}
+
+// glyphCode returns the character code that selects glyph gid. This is the
+// glyph index itself except in CID-keyed CFF fonts, in which glyphs are
+// selected by CID.
+func (u *utf8FontType) glyphCode(gid uint16) uint16 {
+	if int(gid) < len(u.ttf.CIDs) {
+		return u.ttf.CIDs[gid]
+	}
+	return gid
+}
+
+// glyphWidth returns the advance width of the specified glyph in thousandths
+// of the font size.
+func (u *utf8FontType) glyphWidth(gid uint16) int {
+	if int(gid) < len(u.ttf.Widths) {
+		return round(u.k * float64(u.ttf.Widths[gid]))
+	}
+	return 0
+}
+
+// glyphData returns the outline data of the specified glyph from the glyf
+// table, or nil if the glyph is empty or the font does not have TrueType
+// outlines
+func (u *utf8FontType) glyphData(gid uint16) []byte {
+	if u.loca == nil {
+		u.loca = []uint32{}
+		tables, err := ttfTables(u.data)
+		if err != nil {
+			return nil
+		}
+		head, okHead := tables["head"]
+		loca, okLoca := tables["loca"]
+		glyf, okGlyf := tables["glyf"]
+		if !okHead || !okLoca || !okGlyf || head.length < 54 {
+			return nil
+		}
+		longLoca := binary.BigEndian.Uint16(u.data[head.offset+50:]) != 0
+		data := u.data[loca.offset : loca.offset+loca.length]
+		if longLoca {
+			for j := 0; j+4 <= len(data); j += 4 {
+				u.loca = append(u.loca, binary.BigEndian.Uint32(data[j:]))
+			}
+		} else {
+			for j := 0; j+2 <= len(data); j += 2 {
+				u.loca = append(u.loca, 2*uint32(binary.BigEndian.Uint16(data[j:])))
+			}
+		}
+		u.glyf = u.data[glyf.offset : glyf.offset+glyf.length]
+	}
+	if int(gid)+1 >= len(u.loca) {
+		return nil
+	}
+	start, end := u.loca[gid], u.loca[gid+1]
+	if start >= end || end > uint32(len(u.glyf)) {
+		return nil
+	}
+	return u.glyf[start:end]
+}
+
+// glyphBounds returns the bounding box of the outline of the specified glyph
+// in thousandths of the font size. ok is false if the glyph is empty or if
+// the font does not have TrueType outlines.
+func (u *utf8FontType) glyphBounds(gid uint16) (box fontBoxType, ok bool) {
+	g := u.glyphData(gid)
+	if len(g) < 10 {
+		return
+	}
+	coord := func(pos int) int {
+		return round(u.k * float64(int16(binary.BigEndian.Uint16(g[pos:]))))
+	}
+	return fontBoxType{coord(2), coord(4), coord(6), coord(8)}, true
+}
+
+// glyphKern returns the kerning adjustment between glyphs left and right in
+// thousandths of the font size
+func (u *utf8FontType) glyphKern(left, right uint16) int {
+	if val, ok := u.ttf.Kerning[left][right]; ok {
+		return round(u.k * float64(val))
+	}
+	return 0
+}
+
+// substitute applies the GSUB lookups of the specified features to list and
+// returns the result. Ligatures carry the text of all of their components so
+// that the ToUnicode map of the font remains correct.
+func (u *utf8FontType) substitute(list []glyphType, tags ...string) []glyphType {
+	idxMap := make(map[int]bool)
+	for _, tag := range tags {
+		for _, idx := range u.ttf.gsubFeatures[tag] {
+			idxMap[idx] = true
+		}
+	}
+	indexes := make([]int, 0, len(idxMap))
+	for idx := range idxMap {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+	for _, idx := range indexes {
+		lookup := u.ttf.gsubLookups[idx]
+		// Substitutions only shorten the list, so it can be rewritten in place
+		out := list[:0]
+		for j := 0; j < len(list); j++ {
+			g := list[j]
+			if g.font != "" {
+				// Glyph of a fallback font
+				out = append(out, g)
+				continue
+			}
+			if sub, ok := lookup.single[g.code]; ok {
+				g.code = sub
+				g.wd = u.glyphWidth(sub)
+			} else {
+				for _, lig := range lookup.ligatures[g.code] {
+					n := len(lig.components)
+					if j+n >= len(list) {
+						continue
+					}
+					match := true
+					for k, c := range lig.components {
+						match = match && list[j+1+k].code == c && list[j+1+k].font == ""
+					}
+					if match {
+						var text []rune
+						for _, c := range list[j : j+n+1] {
+							text = append(text, c.text...)
+						}
+						g = glyphType{code: lig.glyph, wd: u.glyphWidth(lig.glyph), text: text, pos: g.pos}
+						j += n
+						break
+					}
+				}
+			}
+			out = append(out, g)
+		}
+		list = out
+	}
+	return list
+}
+
+// AddUTF8Font imports a TrueType or OpenType font with Unicode encoding and
+// makes it available. Unlike AddFont(), no font definition file is required:
+// the font file itself is read and parsed. Text that is subsequently written
+// with this font using Cell(), MultiCell(), Write(), Text() and so on is
+// interpreted as UTF-8, and GetStringWidth() and SplitLines() measure it
+// accordingly. Characters beyond the Basic Multilingual Plane, such as emoji
+// and the supplementary CJK ideographs, are found through the format 12
+// character map of the font. Characters that the font does not contain are
+// shown with its missing glyph unless they are taken from a fallback font
+// (see SetFontFallback()).
+//
+// The font is embedded in the document as a CIDFont with Identity-H encoding
+// and a ToUnicode map so that text can be searched and copied. For fonts with
+// TrueType outlines, only the glyphs that are actually used in the document
+// are embedded. OpenType fonts with PostScript (CFF) outlines are embedded in
+// full and require PDF version 1.6. A font that is not used to write text is
+// not embedded. If text is written with a font whose license does not allow
+// embedding, an error is set when the document is output; such fonts can be
+// drawn with TextOutline().
+//
+// See AddFont() for details about familyStr and styleStr. fileStr specifies
+// the name of the font file; it is loaded from the font directory
+// specified in the call to New() or SetFontLocation().
+//
+// See tutorial 29 for an example of this function.
+func (f *Fpdf) AddUTF8Font(familyStr, styleStr, fileStr string) {
+	if f.err != nil {
+		return
+	}
+	buf, err := ioutil.ReadFile(path.Join(f.fontpath, fileStr))
+	if err != nil {
+		f.err = err
+		return
+	}
+	f.AddUTF8FontFromBytes(familyStr, styleStr, buf)
+}
+
+// AddUTF8FontFromBytes imports a TrueType or OpenType font with Unicode
+// encoding from the contents of the font file, fontBytes, and makes it
+// available. It is equivalent to AddUTF8Font() except that no file is read,
+// so that, for example, fonts that are embedded in an application with
+// go:embed can be used.
+//
+// See tutorial 45 for an example of this function.
+func (f *Fpdf) AddUTF8FontFromBytes(familyStr, styleStr string, fontBytes []byte) {
+	if f.err != nil {
+		return
+	}
+	familyStr = strings.ToLower(familyStr)
+	styleStr = strings.ToUpper(styleStr)
+	if styleStr == "IB" {
+		styleStr = "BI"
+	}
+	fontkey := familyStr + styleStr
+	_, ok := f.fonts[fontkey]
+	if ok {
+		return
+	}
+	ttf, err := ttfParseBytes(fontBytes)
+	if err != nil {
+		f.err = err
+		return
+	}
+	utf := &utf8FontType{
+		data: fontBytes,
+		ttf:  ttf,
+		k:    1000.0 / float64(ttf.UnitsPerEm),
+		used: make(map[uint16][]rune),
+	}
+	info := getInfoFromTtf(ttf)
+	makeFontDescriptor(&info)
+	// Glyph indexes do not follow the standard Latin character set
+	info.Desc.Flags = (info.Desc.Flags &^ (1 << 5)) | (1 << 2)
+	var def fontDefType
+	def.Tp = "UTF8"
+	def.Name = info.FontName
+	def.Desc = info.Desc
+	def.Up = info.UnderlinePosition
+	def.Ut = info.UnderlineThickness
+	def.OriginalSize = len(fontBytes)
+	for j := range def.Cw {
+		def.Cw[j] = utf.glyphWidth(utf.glyphIndex(rune(j)))
+	}
+	def.utf8 = utf
+	def.I = len(f.fonts)
+	f.fonts[fontkey] = def
+}
+
+// subsetTag returns the six letter prefix that identifies a font subset. It
+// is derived from the glyphs in the subset so that output is reproducible.
+func (u *utf8FontType) subsetTag(gids []uint16) string {
+	h := md5.New()
+	binary.Write(h, binary.BigEndian, gids)
+	sum := h.Sum(nil)
+	tag := make([]byte, 6)
+	for j := range tag {
+		tag[j] = 'A' + sum[j]%26
+	}
+	return string(tag)
+}
+
+// usedGlyphs returns the sorted list of glyphs used in the document
+func (u *utf8FontType) usedGlyphs() (gids []uint16) {
+	gids = make([]uint16, 0, len(u.used)+1)
+	if _, ok := u.used[0]; !ok {
+		gids = append(gids, 0)
+	}
+	for gid := range u.used {
+		gids = append(gids, gid)
+	}
+	sort.Sort(uint16Slice(gids))
+	return
+}
+
+type uint16Slice []uint16
+
+func (s uint16Slice) Len() int           { return len(s) }
+func (s uint16Slice) Less(i, j int) bool { return s[i] < s[j] }
+func (s uint16Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// Output the objects that make up a Unicode font: the Type0 font, its
+// CIDFont descendant, widths, font descriptor, ToUnicode map and font
+// program. The Type0 font object is output first so that font.N refers to it.
+func (f *Fpdf) putUTF8Font(font fontDefType) {
+	utf := font.utf8
+	gids := utf.usedGlyphs()
+	name := font.Name
+	// A font that is not used to write text, for example because it is only
+	// used for text outlines, is not embedded
+	embed := len(utf.used) > 0
+	if embed && !utf.ttf.Embeddable {
+		f.err = fmt.Errorf("font license does not allow embedding: %s", font.Name)
+		return
+	}
+	if embed && !utf.ttf.CFF {
+		name = utf.subsetTag(gids) + "+" + name
+	}
+	// Type0 font
+	f.newobj()
+	f.out("<</Type /Font /Subtype /Type0")
+	f.outf("/BaseFont /%s", name)
+	f.out("/Encoding /Identity-H")
+	f.outf("/DescendantFonts [%d 0 R]", f.n+1)
+	f.outf("/ToUnicode %d 0 R", f.n+2)
+	f.out(">>")
+	f.out("endobj")
+	// CIDFont
+	f.newobj()
+	if utf.ttf.CFF {
+		f.out("<</Type /Font /Subtype /CIDFontType0")
+	} else {
+		f.out("<</Type /Font /Subtype /CIDFontType2")
+	}
+	f.outf("/BaseFont /%s", name)
+	f.out("/CIDSystemInfo <</Registry (Adobe) /Ordering (Identity) /Supplement 0>>")
+	f.outf("/FontDescriptor %d 0 R", f.n+2)
+	f.outf("/DW %d", font.Desc.MissingWidth)
+	f.out(utf.widthArray(gids))
+	if !utf.ttf.CFF {
+		f.out("/CIDToGIDMap /Identity")
+	}
+	f.out(">>")
+	f.out("endobj")
+	// ToUnicode
+	f.newobj()
+	cmap := utf.toUnicodeCMap(gids)
+	f.putcompressedstream(cmap, "")
+	f.out("endobj")
+	// Descriptor
+	f.newobj()
+	var s fmtBuffer
+	s.printf("<</Type /FontDescriptor /FontName /%s ", name)
+	s.printf("/Ascent %d ", font.Desc.Ascent)
+	s.printf("/Descent %d ", font.Desc.Descent)
+	s.printf("/CapHeight %d ", font.Desc.CapHeight)
+	s.printf("/Flags %d ", font.Desc.Flags)
+	s.printf("/FontBBox [%d %d %d %d] ", font.Desc.FontBBox.Xmin, font.Desc.FontBBox.Ymin,
+		font.Desc.FontBBox.Xmax, font.Desc.FontBBox.Ymax)
+	s.printf("/ItalicAngle %d ", font.Desc.ItalicAngle)
+	s.printf("/StemV %d ", font.Desc.StemV)
+	s.printf("/MissingWidth %d", font.Desc.MissingWidth)
+	switch {
+	case !embed:
+		s.printf(">>")
+	case utf.ttf.CFF:
+		s.printf(" /FontFile3 %d 0 R>>", f.n+1)
+	default:
+		s.printf(" /FontFile2 %d 0 R>>", f.n+1)
+	}
+	f.out(s.String())
+	f.out("endobj")
+	if !embed {
+		return
+	}
+	// Font program
+	f.newobj()
+	if utf.ttf.CFF {
+		f.putcompressedstream(utf.data, "/Subtype /OpenType")
+		f.out("endobj")
+		return
+	}
+	data, err := ttfSubset(utf.data, gids)
+	if err != nil {
+		f.err = err
+		return
+	}
+	f.putcompressedstream(data, sprintf("/Length1 %d", len(data)))
+	f.out("endobj")
+}
+
+// Output a stream dictionary and stream, compressing the data if compression
+// is enabled. extraStr, if not empty, is included in the stream dictionary.
+func (f *Fpdf) putcompressedstream(data []byte, extraStr string) {
+	if len(extraStr) > 0 {
+		extraStr = " " + extraStr
+	}
+	if f.compress {
+		data = sliceCompress(data)
+		f.outf("<</Filter /FlateDecode /Length %d%s>>", len(data), extraStr)
+	} else {
+		f.outf("<</Length %d%s>>", len(data), extraStr)
+	}
+	f.putstream(data)
+}
+
+// codeMap returns the sorted character codes of the specified glyphs and the
+// glyph that each code selects
+func (u *utf8FontType) codeMap(gids []uint16) (codes []uint16, gidOf map[uint16]uint16) {
+	gidOf = make(map[uint16]uint16, len(gids))
+	codes = make([]uint16, 0, len(gids))
+	for _, gid := range gids {
+		code := u.glyphCode(gid)
+		gidOf[code] = gid
+		codes = append(codes, code)
+	}
+	sort.Sort(uint16Slice(codes))
+	return
+}
+
+// widthArray returns the /W entry of a CIDFont for the specified glyphs.
+// Glyphs with consecutive codes are grouped into a single array.
+func (u *utf8FontType) widthArray(gids []uint16) string {
+	codes, gidOf := u.codeMap(gids)
+	var s fmtBuffer
+	s.WriteString("/W [")
+	for j := 0; j < len(codes); {
+		k := j + 1
+		for k < len(codes) && codes[k] == codes[k-1]+1 {
+			k++
+		}
+		s.printf("%d [", codes[j])
+		for _, code := range codes[j:k] {
+			s.printf("%d ", u.glyphWidth(gidOf[code]))
+		}
+		s.WriteString("] ")
+		j = k
+	}
+	s.WriteString("]")
+	return s.String()
+}
+
+// toUnicodeCMap returns a CMap that maps the specified glyphs to the Unicode
+// text they represent.
+func (u *utf8FontType) toUnicodeCMap(gids []uint16) []byte {
+	var list []string
+	codes, gidOf := u.codeMap(gids)
+	for _, code := range codes {
+		text, ok := u.used[gidOf[code]]
+		if ok && len(text) > 0 {
+			var hex fmtBuffer
+			for _, v := range utf16.Encode(text) {
+				hex.printf("%04X", v)
+			}
+			list = append(list, sprintf("<%04X> <%s>", code, hex.String()))
+		}
+	}
+	var s fmtBuffer
+	s.WriteString("/CIDInit /ProcSet findresource begin\n")
+	s.WriteString("12 dict begin\n")
+	s.WriteString("begincmap\n")
+	s.WriteString("/CIDSystemInfo <</Registry (Adobe) /Ordering (UCS) /Supplement 0>> def\n")
+	s.WriteString("/CMapName /Adobe-Identity-UCS def\n")
+	s.WriteString("/CMapType 2 def\n")
+	s.WriteString("1 begincodespacerange\n")
+	s.WriteString("<0000> <FFFF>\n")
+	s.WriteString("endcodespacerange\n")
+	for len(list) > 0 {
+		n := len(list)
+		if n > 100 {
+			n = 100
+		}
+		s.printf("%d beginbfchar\n", n)
+		for _, str := range list[:n] {
+			s.printf("%s\n", str)
+		}
+		s.WriteString("endbfchar\n")
+		list = list[n:]
+	}
+	s.WriteString("endcmap\n")
+	s.WriteString("CMapName currentdict /CMap defineresource pop\n")
+	s.WriteString("end\n")
+	s.WriteString("end")
+	return s.Bytes()
+}
+
+// Tables that are retained in a TrueType subset. Others, for example names
+// and layout tables, are not used by PDF viewers for CID fonts.
+var ttfSubsetTables = []string{"cvt ", "fpgm", "glyf", "head", "hhea", "hmtx", "loca", "maxp", "prep"}
+
+// ttfEmptyCmap is a character map with a single format 4 subtable that maps
+// no characters. Glyphs of a CID font are selected by index, but some
+// consumers of embedded fonts insist on the presence of a cmap table.
+var ttfEmptyCmap = []byte{
+	0, 0, 0, 1, // version, number of subtables
+	0, 3, 0, 1, 0, 0, 0, 12, // Windows Unicode BMP, offset
+	0, 4, 0, 24, 0, 0, // format, length, language
+	0, 2, 0, 2, 0, 0, 0, 0, // segCountX2, searchRange, entrySelector, rangeShift
+	0xff, 0xff, 0, 0, // endCode, reservedPad
+	0xff, 0xff, 0, 1, 0, 0, // startCode, idDelta, idRangeOffset
+}
+
+type ttfTableType struct {
+	offset, length uint32
+}
+
+// ttfTables returns the table directory of the TrueType font in data
+func ttfTables(data []byte) (tables map[string]ttfTableType, err error) {
+	if len(data) < 12 {
+		err = fmt.Errorf("font file is too short")
+		return
+	}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+16*numTables {
+		err = fmt.Errorf("font table directory is truncated")
+		return
+	}
+	tables = make(map[string]ttfTableType)
+	for j := 0; j < numTables; j++ {
+		rec := data[12+16*j:]
+		var tbl ttfTableType
+		tbl.offset = binary.BigEndian.Uint32(rec[8:])
+		tbl.length = binary.BigEndian.Uint32(rec[12:])
+		if uint64(tbl.offset)+uint64(tbl.length) > uint64(len(data)) {
+			err = fmt.Errorf("font table %s extends beyond end of file", string(rec[0:4]))
+			return
+		}
+		tables[string(rec[0:4])] = tbl
+	}
+	return
+}
+
+// ttfSubset returns a TrueType font program that contains only the glyph
+// outlines of the specified glyphs and the components they refer to. Glyph
+// indexes are retained, so the outlines of unused glyphs are simply left
+// empty.
+func ttfSubset(data []byte, gids []uint16) (sub []byte, err error) {
+	var tables map[string]ttfTableType
+	tables, err = ttfTables(data)
+	if err != nil {
+		return
+	}
+	for _, tag := range []string{"head", "maxp", "loca", "glyf"} {
+		if _, ok := tables[tag]; !ok {
+			err = fmt.Errorf("table not found: %s", tag)
+			return
+		}
+	}
+	tableData := func(tag string) []byte {
+		tbl := tables[tag]
+		return data[tbl.offset : tbl.offset+tbl.length]
+	}
+	head := tableData("head")
+	if len(head) < 54 {
+		err = fmt.Errorf("head table is truncated")
+		return
+	}
+	maxp := tableData("maxp")
+	if len(maxp) < 6 {
+		err = fmt.Errorf("maxp table is truncated")
+		return
+	}
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	longLoca := binary.BigEndian.Uint16(head[50:]) != 0
+	loca := tableData("loca")
+	glyf := tableData("glyf")
+	if (longLoca && len(loca) < 4*(numGlyphs+1)) || (!longLoca && len(loca) < 2*(numGlyphs+1)) {
+		err = fmt.Errorf("loca table is truncated")
+		return
+	}
+	glyphData := func(gid int) []byte {
+		var start, end uint32
+		if longLoca {
+			start = binary.BigEndian.Uint32(loca[4*gid:])
+			end = binary.BigEndian.Uint32(loca[4*gid+4:])
+		} else {
+			start = 2 * uint32(binary.BigEndian.Uint16(loca[2*gid:]))
+			end = 2 * uint32(binary.BigEndian.Uint16(loca[2*gid+2:]))
+		}
+		if start >= end || end > uint32(len(glyf)) {
+			return nil
+		}
+		return glyf[start:end]
+	}
+	// Include glyphs referenced by composite glyphs
+	keep := make(map[int]bool)
+	list := make([]int, 0, len(gids))
+	for _, gid := range gids {
+		list = append(list, int(gid))
+	}
+	for len(list) > 0 {
+		gid := list[len(list)-1]
+		list = list[:len(list)-1]
+		if keep[gid] || gid >= numGlyphs {
+			continue
+		}
+		keep[gid] = true
+		list = append(list, ttfGlyphComponents(glyphData(gid))...)
+	}
+	// Rebuild glyph data and location tables
+	var newGlyf fmtBuffer
+	newLoca := make([]byte, 4*(numGlyphs+1))
+	for gid := 0; gid < numGlyphs; gid++ {
+		binary.BigEndian.PutUint32(newLoca[4*gid:], uint32(newGlyf.Len()))
+		if keep[gid] {
+			g := glyphData(gid)
+			newGlyf.Write(g)
+			for newGlyf.Len()%4 != 0 {
+				newGlyf.WriteByte(0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(newLoca[4*numGlyphs:], uint32(newGlyf.Len()))
+	newHead := make([]byte, len(head))
+	copy(newHead, head)
+	binary.BigEndian.PutUint32(newHead[8:], 0)  // checkSumAdjustment
+	binary.BigEndian.PutUint16(newHead[50:], 1) // long loca offsets
+	newTables := make(map[string][]byte)
+	for _, tag := range ttfSubsetTables {
+		if _, ok := tables[tag]; ok {
+			newTables[tag] = tableData(tag)
+		}
+	}
+	newTables["cmap"] = ttfEmptyCmap
+	if post, ok := tables["post"]; ok && post.length >= 32 {
+		// Keep the header of the PostScript table but drop the glyph names
+		newPost := make([]byte, 32)
+		copy(newPost, tableData("post"))
+		binary.BigEndian.PutUint32(newPost, 0x00030000)
+		newTables["post"] = newPost
+	}
+	newTables["head"] = newHead
+	newTables["loca"] = newLoca
+	newTables["glyf"] = newGlyf.Bytes()
+	sub = ttfAssemble(binary.BigEndian.Uint32(data[0:]), newTables)
+	// The checksum adjustment makes the checksum of the entire font equal to a
+	// fixed value
+	headPos := ttfTableOffset(sub, "head")
+	binary.BigEndian.PutUint32(sub[headPos+8:], 0xB1B0AFBA-ttfChecksum(sub))
+	return
+}
+
+// ttfGlyphComponents returns the glyph indexes referred to by a composite
+// glyph. Nil is returned for simple glyphs.
+func ttfGlyphComponents(g []byte) (list []int) {
+	const (
+		argsAreWords   = 0x0001
+		haveScale      = 0x0008
+		moreComponents = 0x0020
+		haveXYScale    = 0x0040
+		haveTwoByTwo   = 0x0080
+	)
+	if len(g) < 10 || int16(binary.BigEndian.Uint16(g)) >= 0 {
+		return
+	}
+	pos := 10
+	for pos+4 <= len(g) {
+		flags := binary.BigEndian.Uint16(g[pos:])
+		list = append(list, int(binary.BigEndian.Uint16(g[pos+2:])))
+		pos += 4
+		if flags&argsAreWords != 0 {
+			pos += 4
+		} else {
+			pos += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			pos += 2
+		case flags&haveXYScale != 0:
+			pos += 4
+		case flags&haveTwoByTwo != 0:
+			pos += 8
+		}
+		if flags&moreComponents == 0 {
+			break
+		}
+	}
+	return
+}
+
+// ttfChecksum returns the sum of the specified data as big-endian 32-bit
+// values. The data is zero-padded to a multiple of four bytes.
+func ttfChecksum(data []byte) (sum uint32) {
+	for len(data) >= 4 {
+		sum += binary.BigEndian.Uint32(data)
+		data = data[4:]
+	}
+	if len(data) > 0 {
+		var last [4]byte
+		copy(last[:], data)
+		sum += binary.BigEndian.Uint32(last[:])
+	}
+	return
+}
+
+// ttfTableOffset returns the offset of the specified table in an assembled
+// font, or -1 if it is not present
+func ttfTableOffset(data []byte, tag string) int {
+	tables, err := ttfTables(data)
+	if err == nil {
+		if tbl, ok := tables[tag]; ok {
+			return int(tbl.offset)
+		}
+	}
+	return -1
+}
+
+// ttfAssemble returns a font file with the specified version and tables
+func ttfAssemble(version uint32, tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	numTables := len(tags)
+	entrySelector := 0
+	for (2 << uint(entrySelector)) <= numTables {
+		entrySelector++
+	}
+	searchRange := (1 << uint(entrySelector)) * 16
+	var buf fmtBuffer
+	binary.Write(&buf, binary.BigEndian, version)
+	binary.Write(&buf, binary.BigEndian, []uint16{uint16(numTables), uint16(searchRange),
+		uint16(entrySelector), uint16(numTables*16 - searchRange)})
+	offset := 12 + 16*numTables
+	for _, tag := range tags {
+		tbl := tables[tag]
+		buf.WriteString(tag)
+		binary.Write(&buf, binary.BigEndian, []uint32{ttfChecksum(tbl), uint32(offset), uint32(len(tbl))})
+		offset += (len(tbl) + 3) &^ 3
+	}
+	for _, tag := range tags {
+		tbl := tables[tag]
+		buf.Write(tbl)
+		for buf.Len()%4 != 0 {
+			buf.WriteByte(0)
+		}
+	}
+	return buf.Bytes()
+}